@@ -0,0 +1,210 @@
+package lars
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/form"
+)
+
+const (
+	paramTag  = "param"
+	queryTag  = "query"
+	headerTag = "header"
+)
+
+var bindTags = []string{paramTag, queryTag, headerTag}
+
+// bindField is a struct field tagged for binding
+type bindField struct {
+	index []int
+	tag   string
+	name  string
+}
+
+// binder populates the struct fields tagged for binding, using a private cache
+// of each structs tagged fields, converting the values of each field using the
+// built in form decoder so that custom types registered on it are supported.
+type binder struct {
+	m     sync.RWMutex
+	cache map[reflect.Type][]bindField
+}
+
+var structBinder = &binder{cache: make(map[reflect.Type][]bindField)}
+
+// fields returns the fields of the struct type tagged for binding
+func (b *binder) fields(typ reflect.Type) []bindField {
+
+	b.m.RLock()
+	fields, ok := b.cache[typ]
+	b.m.RUnlock()
+
+	if ok {
+		return fields
+	}
+
+	fields = appendBindFields(nil, typ, nil)
+
+	b.m.Lock()
+	b.cache[typ] = fields
+	b.m.Unlock()
+
+	return fields
+}
+
+// appendBindFields appends the fields of typ tagged for binding, including
+// those of embedded structs.
+func appendBindFields(fields []bindField, typ reflect.Type, index []int) []bindField {
+
+	for i := 0; i < typ.NumField(); i++ {
+
+		fld := typ.Field(i)
+
+		if fld.PkgPath != blank && !fld.Anonymous {
+			continue
+		}
+
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+
+		tagged := false
+
+		for _, tag := range bindTags {
+
+			name := fld.Tag.Get(tag)
+			if name == blank {
+				continue
+			}
+
+			tagged = true
+
+			if i := strings.IndexByte(name, ','); i != -1 {
+				name = name[:i]
+			}
+
+			if name == "-" || name == blank {
+				continue
+			}
+
+			// header names are case insensitive, so names are canonicalized
+			// to match the keys of http.Header
+			if tag == headerTag {
+				name = http.CanonicalHeaderKey(name)
+			}
+
+			fields = append(fields, bindField{index: idx, tag: tag, name: name})
+		}
+
+		if !tagged && fld.Anonymous && fld.Type.Kind() == reflect.Struct {
+			fields = appendBindFields(fields, fld.Type, idx)
+		}
+	}
+
+	return fields
+}
+
+// bind populates the fields of v tagged with tag from the values
+func (b *binder) bind(tag string, values url.Values, v interface{}) error {
+
+	val := reflect.ValueOf(v)
+
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return &form.InvalidDecoderError{Type: reflect.TypeOf(v)}
+	}
+
+	initFormDecoder()
+
+	val = val.Elem()
+
+	var errs form.DecodeErrors
+
+	for _, f := range b.fields(val.Type()) {
+
+		if f.tag != tag {
+			continue
+		}
+
+		vals, ok := values[f.name]
+		if !ok {
+			continue
+		}
+
+		fv := val.FieldByIndex(f.index)
+
+		if err := formDecoder.Decode(fv.Addr().Interface(), url.Values{blank: vals}); err != nil {
+
+			if errs == nil {
+				errs = make(form.DecodeErrors)
+			}
+
+			if de, ok := err.(form.DecodeErrors); ok && len(de) == 1 {
+				for _, e := range de {
+					err = e
+				}
+			}
+
+			errs[f.name] = err
+		}
+	}
+
+	if errs != nil {
+		return errs
+	}
+
+	return nil
+}
+
+// BindParams decodes the URL params into v, only fields tagged with the
+// param name are populated i.e. `param:"id"`; custom types registered on
+// the built in form decoder are supported.
+func (c *Ctx) BindParams(v interface{}) error {
+
+	values := make(url.Values, len(c.params))
+
+	for _, p := range c.params {
+		values.Add(p.Key, p.Value)
+	}
+
+	return structBinder.bind(paramTag, values, v)
+}
+
+// BindQuery decodes the URL query params into v, only fields tagged with the
+// query param name are populated i.e. `query:"page"`; custom types registered
+// on the built in form decoder are supported.
+func (c *Ctx) BindQuery(v interface{}) error {
+	return structBinder.bind(queryTag, c.QueryParams(), v)
+}
+
+// BindHeaders decodes the request headers into v, only fields tagged with the
+// header name are populated i.e. `header:"X-Tenant"`; names are case insensitive
+// and custom types registered on the built in form decoder are supported.
+func (c *Ctx) BindHeaders(v interface{}) error {
+	return structBinder.bind(headerTag, url.Values(c.request.Header), v)
+}
+
+// Bind populates v from the request body, using Decode, and then the URL params,
+// query params and headers using BindParams, BindQuery and BindHeaders. The body is
+// only decoded when the request has a Content-Type or body; URL params and query
+// params aren't included in the body decoding, use their tags instead.
+func (c *Ctx) Bind(maxMemory int64, v interface{}) (err error) {
+
+	if c.request.Header.Get(ContentType) != blank || c.request.ContentLength > 0 {
+		if err = c.parent.Decode(false, maxMemory, v); err != nil {
+			return
+		}
+	}
+
+	if err = c.BindParams(v); err != nil {
+		return
+	}
+
+	if err = c.BindQuery(v); err != nil {
+		return
+	}
+
+	return c.BindHeaders(v)
+}
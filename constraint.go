@@ -0,0 +1,181 @@
+package lars
+
+import (
+	"regexp"
+	"strconv"
+)
+
+const (
+	constraintStart = '<'
+	constraintEnd   = '>'
+)
+
+// paramConstraint validates a single URL params value
+type paramConstraint struct {
+	pattern string
+	match   func(value string) bool
+}
+
+// constraintTypes are the built in named constraints that can be
+// used in place of a regular expression i.e. /users/:id<int>
+var constraintTypes = map[string]func(value string) bool{
+	"int": func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	},
+	"uint": func(value string) bool {
+		_, err := strconv.ParseUint(value, 10, 64)
+		return err == nil
+	},
+	"float": func(value string) bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	},
+	"bool": func(value string) bool {
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	},
+	"alpha": regexp.MustCompile("^[a-zA-Z]+$").MatchString,
+	"alnum": regexp.MustCompile("^[a-zA-Z0-9]+$").MatchString,
+	"uuid":  regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$").MatchString,
+}
+
+func newParamConstraint(pattern string, fullPath string) *paramConstraint {
+
+	if pattern == blank {
+		panic("empty param constraint in path '" + fullPath + "'")
+	}
+
+	if fn, ok := constraintTypes[pattern]; ok {
+		return &paramConstraint{pattern: pattern, match: fn}
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		panic("invalid param constraint '" + pattern + "' in path '" + fullPath + "': " + err.Error())
+	}
+
+	return &paramConstraint{pattern: pattern, match: re.MatchString}
+}
+
+// parseConstraints strips any param constraints i.e. :id<int> or :name<[a-z]+>
+// from the path returning the path as the tree expects it and the constraints
+// in param order; constraints will be nil when no constraints exist.
+func parseConstraints(path string) (stripped string, constraints []*paramConstraint) {
+
+	var params int
+
+	buff := make([]byte, 0, len(path))
+
+	for i := 0; i < len(path); i++ {
+
+		c := path[i]
+		buff = append(buff, c)
+
+		if c == wildByte {
+			params++
+			continue
+		}
+
+		if c != paramByte {
+			continue
+		}
+
+		params++
+
+		// copy param name
+		for i+1 < len(path) && path[i+1] != slashByte && path[i+1] != constraintStart {
+			i++
+			buff = append(buff, path[i])
+		}
+
+		if i+1 == len(path) || path[i+1] != constraintStart {
+			continue
+		}
+
+		end := skipConstraint(path, i+1)
+		if end == -1 {
+			panic("unterminated param constraint in path '" + path + "'")
+		}
+
+		for len(constraints) < params-1 {
+			constraints = append(constraints, nil)
+		}
+
+		constraints = append(constraints, newParamConstraint(path[i+2:end-1], path))
+		i = end - 1
+	}
+
+	if constraints == nil {
+		return path, nil
+	}
+
+	for len(constraints) < params {
+		constraints = append(constraints, nil)
+	}
+
+	return string(buff), constraints
+}
+
+// stripConstraints returns the path without param constraints, or
+// as is if they're invalid.
+func stripConstraints(path string) (stripped string) {
+
+	defer func() {
+		if recover() != nil {
+			stripped = path
+		}
+	}()
+
+	stripped, _ = parseConstraints(path)
+
+	return
+}
+
+// skipConstraint returns the index just after the constraint starting at
+// index i, allowing for nested <> within regular expressions, or i if no
+// constraint starts at i; -1 is returned if the constraint is unterminated.
+func skipConstraint(path string, i int) int {
+
+	if i >= len(path) || path[i] != constraintStart {
+		return i
+	}
+
+	depth := 0
+
+	for ; i < len(path); i++ {
+		if path[i] == constraintStart {
+			depth++
+		} else if path[i] == constraintEnd {
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return -1
+}
+
+// validParams returns whether the provided params, belonging to the route,
+// satisfy all of the routes param constraints; when unescape is set the escaped
+// param values are unescaped before being checked.
+func (m *methodChain) validParams(p Params, unescape bool) bool {
+
+	for i, c := range m.constraints {
+
+		if c == nil {
+			continue
+		}
+
+		value := p[i].Value
+		if unescape {
+			value = unescapePath(value)
+		}
+
+		if !c.match(value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -7,7 +7,9 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -28,6 +30,7 @@ func NewContext(l *LARS) *Ctx {
 
 	c := &Ctx{
 		params: make(Params, l.mostParams),
+		lars:   l,
 	}
 
 	c.response = newResponse(nil, c)
@@ -75,6 +78,37 @@ func (c *Ctx) Param(name string) string {
 	return blank
 }
 
+// ParamInt returns the value of the Param which key matches the given name
+// parsed as an int; the error from strconv is returned if parsing fails.
+// Using an int constraint i.e. /users/:id<int> guarantees the value can be parsed.
+func (c *Ctx) ParamInt(name string) (int, error) {
+	return strconv.Atoi(c.Param(name))
+}
+
+// ParamInt64 returns the value of the Param which key matches the given name
+// parsed as an int64; the error from strconv is returned if parsing fails.
+func (c *Ctx) ParamInt64(name string) (int64, error) {
+	return strconv.ParseInt(c.Param(name), 10, 64)
+}
+
+// ParamUint64 returns the value of the Param which key matches the given name
+// parsed as a uint64; the error from strconv is returned if parsing fails.
+func (c *Ctx) ParamUint64(name string) (uint64, error) {
+	return strconv.ParseUint(c.Param(name), 10, 64)
+}
+
+// ParamFloat64 returns the value of the Param which key matches the given name
+// parsed as a float64; the error from strconv is returned if parsing fails.
+func (c *Ctx) ParamFloat64(name string) (float64, error) {
+	return strconv.ParseFloat(c.Param(name), 64)
+}
+
+// ParamBool returns the value of the Param which key matches the given name
+// parsed as a bool; the error from strconv is returned if parsing fails.
+func (c *Ctx) ParamBool(name string) (bool, error) {
+	return strconv.ParseBool(c.Param(name))
+}
+
 // QueryParams returns the http.Request.URL.Query() values
 // this function is not for convenience, but rather performance
 // URL.Query() reparses the RawQuery every time it's called, but this
@@ -301,6 +335,12 @@ func (c *Ctx) HandlerName() string {
 	return c.handlerName
 }
 
+// RoutePath returns the path, as registered, of the route matched by
+// the current request i.e. /users/:id or blank when no route matched.
+func (c *Ctx) RoutePath() string {
+	return c.routePath
+}
+
 // Stream provides HTTP Streaming
 func (c *Ctx) Stream(step func(w io.Writer) bool) {
 	w := c.response
@@ -321,72 +361,46 @@ func (c *Ctx) Stream(step func(w io.Writer) bool) {
 }
 
 // Attachment is a helper method for returning an attachement file
-// to be downloaded, if you with to open inline see function
+// to be downloaded, if you with to open inline see function Inline.
+// When r is an io.ReadSeeker Range and conditional requests are supported,
+// using the modification time of r if it has a Stat method i.e. *os.File
 func (c *Ctx) Attachment(r io.Reader, filename string) (err error) {
+	return c.serveFile(r, filename, "attachment", modTime(r))
+}
 
-	c.response.Header().Set(ContentDisposition, "attachment;filename="+filename)
-	c.response.Header().Set(ContentType, detectContentType(filename))
-	c.response.WriteHeader(http.StatusOK)
-
-	_, err = io.Copy(c.response, r)
-
-	return
+// AttachmentContent is the same as Attachment but for content with
+// a known modification time, used for Range and conditional requests.
+func (c *Ctx) AttachmentContent(content io.ReadSeeker, filename string, modtime time.Time) error {
+	return c.serveFile(content, filename, "attachment", modtime)
 }
 
 // Inline is a helper method for returning a file inline to
-// be rendered/opened by the browser
+// be rendered/opened by the browser.
+// When r is an io.ReadSeeker Range and conditional requests are supported,
+// using the modification time of r if it has a Stat method i.e. *os.File
 func (c *Ctx) Inline(r io.Reader, filename string) (err error) {
-
-	c.response.Header().Set(ContentDisposition, "inline;filename="+filename)
-	c.response.Header().Set(ContentType, detectContentType(filename))
-	c.response.WriteHeader(http.StatusOK)
-
-	_, err = io.Copy(c.response, r)
-
-	return
+	return c.serveFile(r, filename, "inline", modTime(r))
 }
 
-// Decode takes the request and attempts to discover it's content type via
-// the http headers and then decode the request body into the provided struct.
-// Example if header was "application/json" would decode using
-// json.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v).
-func (c *Ctx) Decode(includeFormQueryParams bool, maxMemory int64, v interface{}) (err error) {
+// InlineContent is the same as Inline but for content with
+// a known modification time, used for Range and conditional requests.
+func (c *Ctx) InlineContent(content io.ReadSeeker, filename string, modtime time.Time) error {
+	return c.serveFile(content, filename, "inline", modtime)
+}
 
-	initFormDecoder()
+func (c *Ctx) serveFile(r io.Reader, filename string, disposition string, modtime time.Time) (err error) {
 
-	typ := c.request.Header.Get(ContentType)
+	c.response.Header().Set(ContentDisposition, contentDisposition(disposition, filename))
+	c.response.Header().Set(ContentType, detectContentType(filename))
 
-	if idx := strings.Index(typ, ";"); idx != -1 {
-		typ = typ[:idx]
+	if rs, ok := r.(io.ReadSeeker); ok {
+		http.ServeContent(c.response, c.request, filename, modtime, rs)
+		return
 	}
 
-	switch typ {
-
-	case ApplicationJSON:
-		err = json.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v)
-
-	case ApplicationXML:
-		err = xml.NewDecoder(io.LimitReader(c.request.Body, maxMemory)).Decode(v)
-
-	case ApplicationForm:
-
-		if err = c.ParseForm(); err == nil {
-			if includeFormQueryParams {
-				err = formDecoder.Decode(v, c.request.Form)
-			} else {
-				err = formDecoder.Decode(v, c.request.PostForm)
-			}
-		}
+	c.response.WriteHeader(http.StatusOK)
 
-	case MultipartForm:
+	_, err = io.Copy(c.response, r)
 
-		if err = c.ParseMultipartForm(maxMemory); err == nil {
-			if includeFormQueryParams {
-				err = formDecoder.Decode(v, c.request.Form)
-			} else {
-				err = formDecoder.Decode(v, c.request.MultipartForm.Value)
-			}
-		}
-	}
 	return
 }
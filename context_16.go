@@ -19,6 +19,11 @@ type Context interface {
 	Response() *Response
 	WebSocket() *websocket.Conn
 	Param(name string) string
+	ParamInt(name string) (int, error)
+	ParamInt64(name string) (int64, error)
+	ParamUint64(name string) (uint64, error)
+	ParamFloat64(name string) (float64, error)
+	ParamBool(name string) (bool, error)
 	QueryParams() url.Values
 	ParseForm() error
 	ParseMultipartForm(maxMemory int64) error
@@ -36,7 +41,9 @@ type Context interface {
 	ClientIP() (clientIP string)
 	AcceptedLanguages(lowercase bool) []string
 	HandlerName() string
+	RoutePath() string
 	Stream(step func(w io.Writer) bool)
+	SSE() *SSEWriter
 	JSON(int, interface{}) error
 	JSONBytes(int, []byte) error
 	JSONP(int, interface{}, string) error
@@ -44,9 +51,18 @@ type Context interface {
 	XMLBytes(int, []byte) error
 	Text(int, string) error
 	TextBytes(int, []byte) error
+	Negotiate(code int, data interface{}, offers ...string) error
+	CheckPreconditions(etag string, modtime time.Time) bool
 	Attachment(r io.Reader, filename string) (err error)
 	Inline(r io.Reader, filename string) (err error)
+	AttachmentContent(content io.ReadSeeker, filename string, modtime time.Time) error
+	InlineContent(content io.ReadSeeker, filename string, modtime time.Time) error
 	Decode(includeFormQueryParams bool, maxMemory int64, v interface{}) (err error)
+	DecodeAndValidate(includeFormQueryParams bool, maxMemory int64, v interface{}) error
+	Bind(maxMemory int64, v interface{}) error
+	BindParams(v interface{}) error
+	BindQuery(v interface{}) error
+	BindHeaders(v interface{}) error
 	BaseContext() *Ctx
 }
 
@@ -62,7 +78,10 @@ type Ctx struct {
 	queryParams         url.Values
 	handlers            HandlersChain
 	parent              Context
+	lars                *LARS
 	handlerName         string
+	routePath           string
+	mountPath           string
 	index               int
 	formParsed          bool
 	multipartFormParsed bool
@@ -77,6 +96,9 @@ func (c *Ctx) RequestStart(w http.ResponseWriter, r *http.Request) {
 	c.netContext = context.Background() // in go 1.7 will call r.Context(), netContext will go away and be replaced with the Request objects Context
 	c.index = -1
 	c.handlers = nil
+	c.handlerName = blank
+	c.routePath = blank
+	c.mountPath = blank
 	c.formParsed = false
 	c.multipartFormParsed = false
 }
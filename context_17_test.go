@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -175,3 +176,32 @@ func TestNativeContextUsingCtx(t *testing.T) {
 	Equal(t, val1, "testval1")
 	Equal(t, val2, "testval2")
 }
+
+func TestSSECancellation(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	l := New()
+	l.Get("/events", func(c Context) {
+
+		sse := c.SSE()
+		defer sse.Close()
+
+		sse.KeepAlive(time.Millisecond)
+
+		Equal(t, sse.Send("", "1", "first", 0), nil)
+
+		cancel()
+		<-sse.Done()
+
+		Equal(t, sse.Send("", "2", "second", 0), context.Canceled)
+	})
+
+	r, _ := http.NewRequest(GET, "/events", nil)
+	r = r.WithContext(ctx)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, strings.Contains(w.Body.String(), "second"), false)
+}
@@ -2,15 +2,19 @@ package lars
 
 import (
 	"bytes"
+	"encoding/json"
 	"encoding/xml"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	. "gopkg.in/go-playground/assert.v1"
 )
@@ -212,6 +216,24 @@ func TestHandlerName(t *testing.T) {
 	MatchRegex(t, body, "^(.*/vendor/)?github.com/go-playground/lars.HandlerForName$")
 }
 
+func TestRoutePath(t *testing.T) {
+	l := New()
+	l.Get("/users/:id<int>", func(c Context) {
+		c.Text(http.StatusOK, c.RoutePath())
+	})
+	l.Use(func(c Context) {
+		c.Text(http.StatusNotFound, c.RoutePath())
+	})
+
+	code, body := request(GET, "/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/users/:id<int>")
+
+	code, body = request(GET, "/unknown", l)
+	Equal(t, code, http.StatusNotFound)
+	Equal(t, body, "")
+}
+
 func TestQueryParams(t *testing.T) {
 	l := New()
 	l.Get("/home/:id", func(c Context) {
@@ -510,7 +532,7 @@ func TestAttachment(t *testing.T) {
 	hf.ServeHTTP(w, r)
 
 	Equal(t, w.Code, http.StatusOK)
-	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo.png")
+	Equal(t, w.Header().Get(ContentDisposition), `attachment; filename="logo.png"`)
 	Equal(t, w.Header().Get(ContentType), "image/png")
 	Equal(t, w.Body.Len(), 3041)
 
@@ -523,7 +545,7 @@ func TestAttachment(t *testing.T) {
 	hf.ServeHTTP(w, r)
 
 	Equal(t, w.Code, http.StatusOK)
-	Equal(t, w.Header().Get(ContentDisposition), "attachment;filename=logo")
+	Equal(t, w.Header().Get(ContentDisposition), `attachment; filename="logo"`)
 	Equal(t, w.Header().Get(ContentType), "application/octet-stream")
 	Equal(t, w.Body.Len(), 3041)
 }
@@ -554,7 +576,7 @@ func TestInline(t *testing.T) {
 	hf.ServeHTTP(w, r)
 
 	Equal(t, w.Code, http.StatusOK)
-	Equal(t, w.Header().Get(ContentDisposition), "inline;filename=logo.png")
+	Equal(t, w.Header().Get(ContentDisposition), `inline; filename="logo.png"`)
 	Equal(t, w.Header().Get(ContentType), "image/png")
 	Equal(t, w.Body.Len(), 3041)
 
@@ -567,11 +589,86 @@ func TestInline(t *testing.T) {
 	hf.ServeHTTP(w, r)
 
 	Equal(t, w.Code, http.StatusOK)
-	Equal(t, w.Header().Get(ContentDisposition), "inline;filename=logo")
+	Equal(t, w.Header().Get(ContentDisposition), `inline; filename="logo"`)
 	Equal(t, w.Header().Get(ContentType), "application/octet-stream")
 	Equal(t, w.Body.Len(), 3041)
 }
 
+func TestAttachmentRange(t *testing.T) {
+
+	modtime := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	l := New()
+	l.Get("/dl", func(c Context) {
+		f, _ := os.Open("logo.png")
+		defer f.Close()
+		if err := c.Attachment(f, "logo.png"); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/dl-content", func(c Context) {
+		if err := c.AttachmentContent(strings.NewReader("0123456789"), "report 2016 \"final\".txt", modtime); err != nil {
+			panic(err)
+		}
+	})
+	l.Get("/dl-inline", func(c Context) {
+		if err := c.InlineContent(strings.NewReader("0123456789"), "résumé.txt", modtime); err != nil {
+			panic(err)
+		}
+	})
+
+	hf := l.Serve()
+
+	fi, err := os.Stat("logo.png")
+	Equal(t, err, nil)
+
+	w := serveRequest(GET, "/dl", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentLength), "3041")
+	Equal(t, w.Header().Get(LastModified), fi.ModTime().UTC().Format(http.TimeFormat))
+	Equal(t, w.Header().Get("Accept-Ranges"), "bytes")
+
+	w = serveRequest(GET, "/dl", map[string]string{"Range": "bytes=0-99"}, hf)
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Header().Get("Content-Range"), "bytes 0-99/3041")
+	Equal(t, w.Header().Get(ContentType), "image/png")
+	Equal(t, w.Body.Len(), 100)
+
+	w = serveRequest(GET, "/dl-content", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentDisposition), `attachment; filename="report 2016 \"final\".txt"`)
+	Equal(t, w.Header().Get(ContentLength), "10")
+	Equal(t, w.Body.String(), "0123456789")
+
+	w = serveRequest(GET, "/dl-content", map[string]string{"Range": "bytes=-3"}, hf)
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Body.String(), "789")
+
+	w = serveRequest(GET, "/dl-content", map[string]string{"Range": "bytes=0-1,5-6"}, hf)
+	Equal(t, w.Code, http.StatusPartialContent)
+	MatchRegex(t, w.Header().Get(ContentType), "^multipart/byteranges; boundary=")
+	MatchRegex(t, w.Body.String(), "(?s)01.*56")
+
+	w = serveRequest(GET, "/dl-content", map[string]string{"Range": "bytes=20-30"}, hf)
+	Equal(t, w.Code, http.StatusRequestedRangeNotSatisfiable)
+
+	w = serveRequest(GET, "/dl-content", map[string]string{"Range": "bytes=2-3", "If-Range": modtime.Format(http.TimeFormat)}, hf)
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Body.String(), "23")
+
+	w = serveRequest(GET, "/dl-content", map[string]string{"Range": "bytes=2-3", "If-Range": modtime.Add(-time.Hour).Format(http.TimeFormat)}, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "0123456789")
+
+	w = serveRequest(GET, "/dl-content", map[string]string{IfModifiedSince: modtime.Format(http.TimeFormat)}, hf)
+	Equal(t, w.Code, http.StatusNotModified)
+
+	w = serveRequest(GET, "/dl-inline", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentDisposition), `inline; filename="r_sum_.txt"; filename*=UTF-8''r%C3%A9sum%C3%A9.txt`)
+	Equal(t, w.Header().Get(ContentType), "text/plain; charset=utf-8")
+}
+
 func TestAcceptedLanguages(t *testing.T) {
 	l := New()
 	c := NewContext(l)
@@ -748,3 +845,300 @@ func TestCachedQueryParams(t *testing.T) {
 	Equal(t, val1, "val1")
 	Equal(t, val2, "val2")
 }
+
+func TestDecoderRegistry(t *testing.T) {
+
+	type TestStruct struct {
+		ID   int
+		Name string
+	}
+
+	l := New()
+	l.RegisterDecoder("application/x-csv", func(c Context, includeFormQueryParams bool, maxMemory int64, v interface{}) error {
+
+		b, err := ioutil.ReadAll(io.LimitReader(c.Request().Body, maxMemory))
+		if err != nil {
+			return err
+		}
+
+		fields := strings.Split(string(b), ",")
+		ts := v.(*TestStruct)
+		ts.ID, _ = strconv.Atoi(fields[0])
+		ts.Name = fields[1]
+
+		return nil
+	})
+	l.Post("/decode", func(c Context) error {
+
+		test := new(TestStruct)
+
+		if err := c.Decode(false, 16<<10, test); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusOK, test.Name)
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(POST, "/decode", strings.NewReader("13,Joey"))
+	r.Header.Set(ContentType, "application/X-CSV; charset=utf-8")
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "Joey")
+
+	r, _ = http.NewRequest(POST, "/decode", strings.NewReader("data"))
+	r.Header.Set(ContentType, ApplicationMsgpack)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnsupportedMediaType)
+
+	r, _ = http.NewRequest(POST, "/decode", strings.NewReader("data"))
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnsupportedMediaType)
+
+	c := NewContext(l)
+	c.request, _ = http.NewRequest(POST, "/decode", nil)
+	c.request.Header.Set(ContentType, "application/yaml")
+	Equal(t, c.Decode(false, 16<<10, new(TestStruct)), ErrUnsupportedMediaType)
+}
+
+func TestBind(t *testing.T) {
+
+	type Request struct {
+		ID      int       `param:"id"`
+		Page    int       `query:"page"`
+		Tags    []string  `query:"tag"`
+		Tenant  string    `header:"x-tenant"`
+		Agents  []string  `header:"User-Agent"`
+		Ignored string    `header:"-"`
+		Since   time.Time `query:"since"`
+		Name    string
+		Email   string
+	}
+
+	l := New()
+	l.BuiltInFormDecoder().RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return time.Parse("2006-01-02", vals[0])
+	}, time.Time{})
+
+	var req *Request
+
+	l.Post("/users/:id", func(c Context) error {
+		req = new(Request)
+		return c.Bind(16<<10, req)
+	})
+	l.Get("/users/:id", func(c Context) error {
+		req = new(Request)
+		return c.Bind(16<<10, req)
+	})
+	l.Get("/params/:id", func(c Context) error {
+		req = new(Request)
+		if err := c.BindParams(req); err != nil {
+			return err
+		}
+		if err := c.BindQuery(req); err != nil {
+			return err
+		}
+		return c.BindHeaders(req)
+	})
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(POST, "/users/13?page=2&tag=a&tag=b&since=2016-01-02&Name=Query", strings.NewReader(`{"Name":"Joey","Email":"joey@example.com"}`))
+	r.Header.Set(ContentType, ApplicationJSON)
+	r.Header.Set("X-Tenant", "acme")
+	r.Header.Set("User-Agent", "test")
+	r.Header.Set("Ignored", "value")
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, req.ID, 13)
+	Equal(t, req.Page, 2)
+	Equal(t, req.Tags, []string{"a", "b"})
+	Equal(t, req.Tenant, "acme")
+	Equal(t, req.Agents, []string{"test"})
+	Equal(t, req.Ignored, "")
+	Equal(t, req.Since, time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC))
+	Equal(t, req.Name, "Joey")
+	Equal(t, req.Email, "joey@example.com")
+
+	// no body
+	r, _ = http.NewRequest(GET, "/users/14?page=3", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, req.ID, 14)
+	Equal(t, req.Page, 3)
+	Equal(t, req.Name, "")
+
+	r, _ = http.NewRequest(GET, "/params/15?page=4", nil)
+	r.Header.Set("X-TENANT", "acme")
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, req.ID, 15)
+	Equal(t, req.Page, 4)
+	Equal(t, req.Tenant, "acme")
+
+	r, _ = http.NewRequest(GET, "/params/abc", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusInternalServerError)
+
+	r, _ = http.NewRequest(POST, "/users/13", strings.NewReader("data"))
+	r.Header.Set(ContentType, ApplicationMsgpack)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusUnsupportedMediaType)
+
+	// fields tagged for both the form body and binding, including embedded structs
+	type Embedded struct {
+		Org string `param:"org"`
+	}
+
+	type FormRequest struct {
+		Embedded
+		Name string `form:"name" query:"name"`
+		Age  int    `form:"age"`
+	}
+
+	var formReq *FormRequest
+
+	l.Post("/orgs/:org", func(c Context) error {
+		formReq = new(FormRequest)
+		return c.Bind(16<<10, formReq)
+	})
+
+	r, _ = http.NewRequest(POST, "/orgs/acme", strings.NewReader("name=Joey&age=3"))
+	r.Header.Set(ContentType, ApplicationForm)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, formReq.Org, "acme")
+	Equal(t, formReq.Name, "Joey")
+	Equal(t, formReq.Age, 3)
+
+	r, _ = http.NewRequest(POST, "/orgs/acme?name=Query", strings.NewReader("name=Joey"))
+	r.Header.Set(ContentType, ApplicationForm)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, formReq.Name, "Query")
+
+	var c Ctx
+	NotEqual(t, c.BindParams(FormRequest{}), nil)
+}
+
+func TestBuiltInFormDecoderTagName(t *testing.T) {
+
+	type JSONTagged struct {
+		Name string `json:"name"`
+		ID   int    `param:"id"`
+	}
+
+	l := New()
+	l.BuiltInFormDecoder().SetTagName("json")
+	defer l.BuiltInFormDecoder().SetTagName("form")
+
+	var req *JSONTagged
+
+	l.Post("/users/:id", func(c Context) error {
+		req = new(JSONTagged)
+		return c.Bind(16<<10, req)
+	})
+
+	r, _ := http.NewRequest(POST, "/users/13", strings.NewReader("name=Joey"))
+	r.Header.Set(ContentType, ApplicationForm)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, req.Name, "Joey")
+	Equal(t, req.ID, 13)
+}
+
+func TestDecodeBodyLimits(t *testing.T) {
+
+	type TestStruct struct {
+		ID    int
+		Name  string
+		Value interface{}
+	}
+
+	var test *TestStruct
+
+	l := New()
+	l.Post("/decode", func(c Context) error {
+		test = new(TestStruct)
+		return c.Decode(false, 32, test)
+	})
+	l.Post("/unlimited", func(c Context) error {
+		test = new(TestStruct)
+		return c.Decode(false, 0, test)
+	})
+	l.Post("/read", func(c Context) error {
+		_, err := ioutil.ReadAll(c.Request().Body)
+		return err
+	})
+
+	hf := l.Serve()
+
+	decodeRequest := func(path string, typ string, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(POST, path, strings.NewReader(body))
+		r.Header.Set(ContentType, typ)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := decodeRequest("/decode", ApplicationJSON, `{"ID":13,"Name":"Joey"}`)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, test.Name, "Joey")
+
+	w = decodeRequest("/decode", ApplicationJSON, `{"ID":13,"Name":"Joey Bloggs the third"}`)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+	Equal(t, w.Body.String(), `{"status":413,"code":"body_too_large","message":"Request Entity Too Large"}`)
+
+	w = decodeRequest("/decode", ApplicationXML, `<TestStruct><ID>13</ID><Name>Joey Bloggs</Name></TestStruct>`)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+	// only streaming decoders are limited, maxMemory is the multipart in-memory threshold
+	w = decodeRequest("/decode", ApplicationForm, "Name=Joey+Bloggs+the+third+of+his+name")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, test.Name, "Joey Bloggs the third of his name")
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	writer.WriteField("Name", "Joey")
+	part, _ := writer.CreateFormFile("file", "upload.txt")
+	part.Write(bytes.Repeat([]byte("a"), 4096))
+	writer.Close()
+
+	w = decodeRequest("/decode", writer.FormDataContentType(), body.String())
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, test.Name, "Joey")
+
+	w = decodeRequest("/unlimited", ApplicationJSON, `{"ID":13,"Name":"Joey Bloggs the third","Value":1}`)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, test.Value, float64(1))
+
+	l.SetMaxBodySize(40)
+
+	w = decodeRequest("/unlimited", ApplicationJSON, `{"ID":13,"Name":"Joey Bloggs the third","Value":1}`)
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+	w = decodeRequest("/read", TextPlain, strings.Repeat("a", 40))
+	Equal(t, w.Code, http.StatusOK)
+
+	w = decodeRequest("/read", TextPlain, strings.Repeat("a", 41))
+	Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+	l.SetMaxBodySize(0)
+	l.SetDecodeJSONUseNumber(true)
+
+	w = decodeRequest("/unlimited", ApplicationJSON, `{"Value":1}`)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, test.Value, json.Number("1"))
+}
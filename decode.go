@@ -0,0 +1,218 @@
+package lars
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecoderFunc decodes the request body into v; maxMemory is the max body size for
+// streaming decoders, such as JSON and XML, and the in-memory threshold for multipart
+// forms. includeFormQueryParams is passed through from Decode for decoders, such as
+// the form decoders, which can also decode query params.
+type DecoderFunc func(c Context, includeFormQueryParams bool, maxMemory int64, v interface{}) error
+
+// ErrBodyTooLarge is returned by Decode when the request body exceeds the max size
+// allowed, either the maxMemory passed to Decode for JSON and XML bodies or the router
+// wide max body size; when returned from a handler it's rendered as a 413 Request
+// Entity Too Large by the DefaultErrorHandler.
+var ErrBodyTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "body_too_large", "")
+
+// ErrUnsupportedMediaType is returned by Decode when no decoder is registered for the
+// requests Content-Type; when returned from a handler it's rendered as a
+// 415 Unsupported Media Type by the DefaultErrorHandler.
+var ErrUnsupportedMediaType = NewHTTPError(http.StatusUnsupportedMediaType, "unsupported_media_type", "")
+
+var (
+	jsonDecoder = func(c Context, includeFormQueryParams bool, maxMemory int64, v interface{}) error {
+
+		l := c.BaseContext().lars
+		body := limitBody(c, maxMemory)
+		dec := json.NewDecoder(body)
+
+		if l.jsonUseNumber {
+			dec.UseNumber()
+		}
+
+		if l.jsonDisallowUnknownFields {
+			disallowUnknownFields(dec)
+		}
+
+		return limitError(body, dec.Decode(v))
+	}
+
+	xmlDecoder = func(c Context, includeFormQueryParams bool, maxMemory int64, v interface{}) error {
+		body := limitBody(c, maxMemory)
+		return limitError(body, xml.NewDecoder(body).Decode(v))
+	}
+
+	formBodyDecoder = func(c Context, includeFormQueryParams bool, maxMemory int64, v interface{}) (err error) {
+
+		if err = c.ParseForm(); err != nil {
+			return
+		}
+
+		initFormDecoder()
+
+		if includeFormQueryParams {
+			return formDecoder.Decode(v, c.Request().Form)
+		}
+
+		return formDecoder.Decode(v, c.Request().PostForm)
+	}
+
+	multipartFormDecoder = func(c Context, includeFormQueryParams bool, maxMemory int64, v interface{}) (err error) {
+
+		if err = c.ParseMultipartForm(maxMemory); err != nil {
+			return
+		}
+
+		initFormDecoder()
+
+		if includeFormQueryParams {
+			return formDecoder.Decode(v, c.Request().Form)
+		}
+
+		return formDecoder.Decode(v, c.Request().MultipartForm.Value)
+	}
+)
+
+// RegisterDecoder registers, or replaces, the decoder used by Decode for requests with the
+// Content-Type mediaType i.e. ApplicationMsgpack or ApplicationProtobuf. JSON, XML, form
+// and multipart form decoders are registered by default.
+func (l *LARS) RegisterDecoder(mediaType string, fn DecoderFunc) {
+	l.decoders[strings.ToLower(mediaType)] = fn
+}
+
+// SetMaxBodySize sets the max size, in bytes, of every request body; reading beyond it
+// returns ErrBodyTooLarge. default 0, no limit
+func (l *LARS) SetMaxBodySize(n int64) {
+	l.maxBodySize = n
+}
+
+// SetDecodeJSONUseNumber tells the JSON decoder used by Decode to decode
+// numbers into an interface{} as a json.Number instead of a float64. default false
+func (l *LARS) SetDecodeJSONUseNumber(set bool) {
+	l.jsonUseNumber = set
+}
+
+// SetDecodeJSONDisallowUnknownFields tells the JSON decoder used by Decode to
+// return an error when an object contains keys not matching any field of
+// the destination. Only supported when built with go1.10+. default false
+func (l *LARS) SetDecodeJSONDisallowUnknownFields(set bool) {
+	l.jsonDisallowUnknownFields = set
+}
+
+// Decode takes the request and attempts to discover it's content type via
+// the http headers and then decode the request body into the provided struct
+// using the decoder registered for it.
+// Example if header was "application/json" would decode using
+// json.NewDecoder(c.request.Body).Decode(v).
+// If no decoder is registered for the content type ErrUnsupportedMediaType is returned
+// and if a JSON or XML body is larger than maxMemory, when > 0, ErrBodyTooLarge; for
+// multipart forms maxMemory is the in-memory threshold, beyond which files are stored
+// on disk.
+func (c *Ctx) Decode(includeFormQueryParams bool, maxMemory int64, v interface{}) (err error) {
+
+	typ := c.request.Header.Get(ContentType)
+
+	if idx := strings.Index(typ, ";"); idx != -1 {
+		typ = typ[:idx]
+	}
+
+	fn, ok := c.lars.decoders[strings.ToLower(strings.TrimSpace(typ))]
+	if !ok {
+		return ErrUnsupportedMediaType
+	}
+
+	return limitError(c.request.Body, fn(c.parent, includeFormQueryParams, maxMemory, v))
+}
+
+// limitBody returns the request body limited to maxMemory bytes, when > 0, for
+// the streaming decoders.
+func limitBody(c Context, maxMemory int64) io.ReadCloser {
+
+	body := c.Request().Body
+
+	if maxMemory > 0 && body != nil {
+		return newMaxBytesReader(body, maxMemory)
+	}
+
+	return body
+}
+
+// limitError returns ErrBodyTooLarge in place of err when the body
+// exceeded its max size.
+func limitError(body io.ReadCloser, err error) error {
+
+	if err != nil && bodyTooLarge(body) {
+		return ErrBodyTooLarge
+	}
+
+	return err
+}
+
+// maxBytesReader is similar to http.MaxBytesReader, but returns ErrBodyTooLarge
+// once more than n bytes have been read.
+type maxBytesReader struct {
+	r        io.ReadCloser
+	n        int64
+	exceeded bool
+}
+
+func newMaxBytesReader(r io.ReadCloser, n int64) io.ReadCloser {
+	return &maxBytesReader{r: r, n: n}
+}
+
+func (m *maxBytesReader) Read(p []byte) (n int, err error) {
+
+	if m.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// read one byte more than remaining to detect exceeding the limit
+	if int64(len(p)) > m.n+1 {
+		p = p[:m.n+1]
+	}
+
+	n, err = m.r.Read(p)
+
+	if int64(n) <= m.n {
+		m.n -= int64(n)
+		return
+	}
+
+	n = int(m.n)
+	m.n = 0
+	m.exceeded = true
+
+	return n, ErrBodyTooLarge
+}
+
+func (m *maxBytesReader) Close() error {
+	return m.r.Close()
+}
+
+// bodyTooLarge returns true if the body, or any body it wraps, exceeded its max size
+func bodyTooLarge(body io.ReadCloser) bool {
+
+	for {
+
+		m, ok := body.(*maxBytesReader)
+		if !ok {
+			return false
+		}
+
+		if m.exceeded {
+			return true
+		}
+
+		body = m.r
+	}
+}
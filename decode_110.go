@@ -0,0 +1,9 @@
+// +build go1.10
+
+package lars
+
+import "encoding/json"
+
+func disallowUnknownFields(dec *json.Decoder) {
+	dec.DisallowUnknownFields()
+}
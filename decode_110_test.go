@@ -0,0 +1,52 @@
+// +build go1.10
+
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestDecodeJSONDisallowUnknownFields(t *testing.T) {
+
+	l := New()
+	l.Post("/decode", func(c Context) error {
+		return c.Decode(false, 0, new(struct{ Name string }))
+	})
+
+	hf := l.Serve()
+
+	decodeRequest := func(body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(POST, "/decode", strings.NewReader(body))
+		r.Header.Set(ContentType, ApplicationJSON)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	w := decodeRequest(`{"Name":"Joey","Age":30}`)
+	Equal(t, w.Code, http.StatusOK)
+
+	l.SetDecodeJSONDisallowUnknownFields(true)
+
+	w = decodeRequest(`{"Name":"Joey"}`)
+	Equal(t, w.Code, http.StatusOK)
+
+	w = decodeRequest(`{"Name":"Joey","Age":30}`)
+	Equal(t, w.Code, http.StatusInternalServerError)
+}
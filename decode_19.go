@@ -0,0 +1,8 @@
+// +build !go1.10
+
+package lars
+
+import "encoding/json"
+
+// DisallowUnknownFields isn't supported by the JSON decoder prior to go1.10
+func disallowUnknownFields(dec *json.Decoder) {}
@@ -44,6 +44,20 @@ example param usage
 	// remaining path if you need to use it in a custom handler...
 	l.Get("/static/*", http.FileServer(http.Dir("static/")))
 
+	// params can be constrained using a built in type int, uint, float, bool, alpha,
+	// alnum, uuid or a regular expression, requests not satisfying the constraint
+	// fall through to the 404/405 handling
+	l.Get("/user/:id<int>/files/:name<[a-z0-9_-]+>", UserFileHandler)
+
+	// typed accessors
+	id, err := c.ParamInt("id")
+
+	// routes can be named and their URL's generated, failing if the
+	// name is unknown or a param is missing
+	l.Get("/user/:id", UserHandler).Name("user.show")
+
+	url, err := l.URL("user.show", lars.Param{Key: "id", Value: "13"}) // "/user/13"
+
 	NOTE: Since this router has only explicit matches, you can not register static routes
 	and parameters for the same path segment. For example you can not register the patterns
 	/user/new and /user/:user for the same request method at the same time. The routing of
@@ -78,6 +92,20 @@ example group definitions
 	// creates a group for admin WITH NO MIDDLEWARE... more can be added using admin.Use()
 	admin := l.GroupWithNone("/admin")
 	admin.Use(SomeAdminSecurityMiddleware)
+
+	// creates a group whose routes only match the host + inherits all middleware
+	// registered using l.Use(); host params are accessed just like URL params
+	// i.e. c.Param("tenant"), requests for unmatched hosts use the routes above.
+	api := l.Host("api.example.com")
+	tenant := l.Host(":tenant.example.com")
+
+	// mounts another independently built lars instance, or any http.Handler, under
+	// the prefix; the groups middleware runs first and the :org param is available
+	// within the billing instances handlers.
+	billing := lars.New()
+	billing.Get("/invoices/:id", ...)
+
+	l.Group("/orgs/:org").Mount("/billing", billing)
 	...
 
 
@@ -149,6 +177,43 @@ currently JSON, XML, FORM + Multipart Form's are support out of the box.
 		log.Println(err)
 	}
 
+	// bodies larger than maxBytes return lars.ErrBodyTooLarge, rendered as a 413;
+	// a max size can also be set for every request body.
+	l.SetMaxBodySize(1 << 20)
+
+	// JSON decoding options
+	l.SetDecodeJSONUseNumber(true)
+	l.SetDecodeJSONDisallowUnknownFields(true) // go1.10+
+
+	// other content types can be supported by registering a decoder, Decode
+	// returns lars.ErrUnsupportedMediaType for any without one. The router wide
+	// max body size also applies to these, reading past it makes Decode return
+	// lars.ErrBodyTooLarge.
+	l.RegisterDecoder(lars.ApplicationMsgpack, func(c lars.Context, includeFormQueryParams bool, maxMemory int64, v interface{}) error {
+		return msgpack.NewDecoder(c.Request().Body).Decode(v)
+	})
+
+	// bind the body, URL params, query params and headers into a single struct;
+	// BindParams, BindQuery and BindHeaders can also be used individually.
+	type UpdateUser struct {
+		ID     int    `param:"id"`
+		Notify bool   `query:"notify"`
+		Tenant string `header:"X-Tenant"`
+		Name   string `json:"name"`
+	}
+
+	if err := c.Bind(maxBytes, &update); err != nil {
+		return err
+	}
+
+	// register a Validator to have DecodeAndValidate validate after decoding;
+	// returning lars.ValidationErrors renders a 422 with the field errors.
+	l.RegisterValidator(myValidator)
+
+	if err := c.DecodeAndValidate(true, maxBytes, &user); err != nil {
+		return err
+	}
+
 
 Misc
 
@@ -160,12 +225,82 @@ misc examples and noteworthy features
 	// like l.Use() does.
 	l.Get(/"home", AdditionalHandler, HomeHandler)
 
+	// handlers may also return an error which is passed to the error handler, by
+	// default DefaultErrorHandler renders *lars.HTTPError's as JSON, XML or text
+	// based on the Accept header and any other error as a 500.
+	l.Get("/user/:id", func(c lars.Context) error {
+		return lars.NewHTTPError(http.StatusNotFound, "user_not_found", "")
+	})
+
+	// set custom error handler
+	l.RegisterErrorHandler(func(c lars.Context, err error) { ... })
+
+	// render data in the format most preferred by the Accept header, by default
+	// JSON, XML and text are offered; more can be registered and the 406
+	// ( Not Acceptable ) response customized.
+	l.RegisterRenderer(lars.ApplicationMsgpack, msgpackRenderer)
+	l.RegisterNotAcceptable(func(c lars.Context, offers []string) error { ... })
+
+	l.Get("/user/:id", func(c lars.Context) error {
+		return c.Negotiate(http.StatusOK, user, lars.ApplicationJSON, lars.ApplicationXML)
+	})
+
+	// recover from panics, see middleware.RecoveryOptions for configuring
+	// stack capture, logging, error reporting and the panic response
+	l.Use(middleware.Recovery(middleware.RecoveryOptions{}))
+
+	// serve static files, with optional index files, directory listing,
+	// precompressed .br/.gz files and SPA fallback to the index file
+	l.Static("/assets", http.Dir("./public"), lars.StaticOptions{MaxAge: time.Hour, Precompressed: true})
+	l.File("/favicon.ico", "./public/favicon.ico")
+
+	// answer conditional requests; the ETag middleware buffers GET and HEAD
+	// responses to generate ETags while CheckPreconditions can be used directly,
+	// i.e. If-Match on a PUT before making changes.
+	l.Use(middleware.ETag(middleware.ETagOptions{}))
+
+	l.Put("/articles/:id", func(c lars.Context) {
+		if c.CheckPreconditions(article.ETag, article.Updated) {
+			return
+		}
+		...
+	})
+
+	// Server-Sent Events, sending stops once the request context is done
+	l.Get("/events", func(c lars.Context) error {
+
+		sse := c.SSE()
+		defer sse.Close()
+
+		sse.KeepAlive(15 * time.Second)
+
+		for {
+			select {
+			case <-sse.Done():
+				return nil
+			case u := <-updates:
+				if err := sse.Send("update", u.ID, u.JSON, 0); err != nil {
+					return err
+				}
+			}
+		}
+	})
+
 	// set custom 404 ( not Found ) handler
 	l.Register404(404Handler)
 
-	// Redirect to or from ending slash if route not found, default is true
+	// Redirect to or from ending slash, or a case-insensitive match, if route not found,
+	// default is true
 	l.SetRedirectTrailingSlash(true)
 
+	// Redirect to the cleaned path i.e. /a/../b and //b to /b if route not found,
+	// default is false
+	l.SetRedirectFixedPath(true)
+
+	// match routes against the escaped path so /files/a%2Fb matches /files/:name,
+	// param values are unescaped unless SetUnescapePathValues(false). default false
+	l.SetUseRawPath(true)
+
 	// Handle 405 ( Method Not allowed ), default is false
 	l.SetHandle405MethodNotAllowed(false)
 
@@ -173,9 +308,33 @@ misc examples and noteworthy features
 	// OPTION handlers take precedence. default true
 	l.SetAutomaticallyHandleOPTIONS(set bool)
 
+	// set custom 405 ( Method Not allowed ) and automatic OPTIONS handlers, both run
+	// behind the global middleware with the Allow header already set.
+	l.Register405(405Handler)
+	l.RegisterAutomaticOPTIONS(OPTIONSHandler)
+
+	// groups can register their own 404 and 405 handlers, run behind the groups
+	// middleware, the group with the longest prefix matching the request is used.
+	api := l.Group("/api")
+	api.Register404(JSON404Handler)
+	api.Register405(JSON405Handler)
+
 	// register custom context
 	l.RegisterContext(ContextFunc)
 
+	// collect route registration errors, such as conflicting routes, instead of
+	// panicking; Validate reports every failed route along with the route and
+	// handler it conflicts with.
+	l.SetCollectRegistrationErrors(true)
+	...
+	if err := l.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// returns every registered route's host, path, method, handler name and tree
+	// depth sorted by host, path and then method; useful for route listings.
+	routes := l.Routes()
+
 	// Register custom handler type, see util.go
 	// https://github.com/go-playground/lars/blob/master/util.go#L62 for example handler
 	// creation
@@ -0,0 +1,95 @@
+package lars
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+)
+
+// ErrorHandlerFunc is the function called with any error returned
+// from a func(Context) error handler.
+type ErrorHandlerFunc func(Context, error)
+
+// HTTPError is an error containing the HTTP status code, an optional application
+// specific error code and message to be rendered by the error handler; Fields
+// contains the field errors of a failed validation.
+type HTTPError struct {
+	XMLName xml.Name         `json:"-" xml:"error"`
+	Status  int              `json:"status" xml:"status"`
+	Code    string           `json:"code,omitempty" xml:"code,omitempty"`
+	Message string           `json:"message" xml:"message"`
+	Fields  ValidationErrors `json:"fields,omitempty" xml:"field,omitempty"`
+}
+
+var _ error = new(HTTPError)
+
+// errorMediaTypes are the media types DefaultErrorHandler can render in order of preference
+var errorMediaTypes = []string{ApplicationJSON, ApplicationXML, TextPlain}
+
+// NewHTTPError returns a new HTTPError, when message is blank the
+// status text of the status code is used.
+func NewHTTPError(status int, code string, message string) *HTTPError {
+
+	if message == blank {
+		message = http.StatusText(status)
+	}
+
+	return &HTTPError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error returns the errors status and message.
+func (e *HTTPError) Error() string {
+	return strconv.Itoa(e.Status) + " " + e.Message
+}
+
+// DefaultErrorHandler is the error handler used when none has been registered
+// using RegisterErrorHandler. *HTTPError's are rendered as is, ValidationErrors
+// as a 422 Unprocessable Entity with the field errors and any other error as a
+// 500 Internal Server Error without exposing the errors details; the format,
+// JSON, XML or text, is chosen based on the requests Accept header.
+// Nothing is rendered when the response has already been committed.
+func DefaultErrorHandler(c Context, err error) {
+
+	if c.Response().Committed() {
+		return
+	}
+
+	var he *HTTPError
+
+	switch e := err.(type) {
+	case *HTTPError:
+		he = e
+	case ValidationErrors:
+		he = e.httpError()
+	default:
+		he = NewHTTPError(http.StatusInternalServerError, blank, blank)
+	}
+
+	switch negotiateMediaType(c.Request().Header.Get(Accept), errorMediaTypes) {
+	case ApplicationXML:
+		c.XML(he.Status, he)
+	case TextPlain:
+		if len(he.Fields) > 0 {
+			c.Text(he.Status, he.Message+"\n"+he.Fields.Error())
+			return
+		}
+		c.Text(he.Status, he.Message)
+	default:
+		c.JSON(he.Status, he)
+	}
+}
+
+// handleError passes the error to the registered error handler
+func (l *LARS) handleError(c Context, err error) {
+
+	if l.errorHandler != nil {
+		l.errorHandler(c, err)
+		return
+	}
+
+	DefaultErrorHandler(c, err)
+}
@@ -0,0 +1,164 @@
+package lars
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestErrorHandlers(t *testing.T) {
+
+	acceptRequest := func(path string, accept string, l *LARS) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		r.Header.Set(Accept, accept)
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	l := New()
+	l.Get("/ok", func(c Context) error {
+		return c.Text(http.StatusOK, "ok")
+	})
+	l.Get("/http-error", func(c Context) error {
+		return NewHTTPError(http.StatusBadRequest, "invalid_id", "id must be numeric")
+	})
+	l.Get("/error", func(c Context) error {
+		return errors.New("database connection lost")
+	})
+	l.Get("/committed", func(c Context) error {
+		c.Text(http.StatusOK, "partial")
+		return errors.New("write failed")
+	})
+
+	w := acceptRequest("/ok", "", l)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "ok")
+
+	w = acceptRequest("/http-error", "", l)
+	Equal(t, w.Code, http.StatusBadRequest)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), `{"status":400,"code":"invalid_id","message":"id must be numeric"}`)
+
+	w = acceptRequest("/http-error", "text/html, application/xml;q=0.9", l)
+	Equal(t, w.Code, http.StatusBadRequest)
+	Equal(t, w.Header().Get(ContentType), ApplicationXMLCharsetUTF8)
+	Equal(t, w.Body.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<error><status>400</status><code>invalid_id</code><message>id must be numeric</message></error>`)
+
+	w = acceptRequest("/http-error", "text/plain", l)
+	Equal(t, w.Code, http.StatusBadRequest)
+	Equal(t, w.Header().Get(ContentType), TextPlainCharsetUTF8)
+	Equal(t, w.Body.String(), "id must be numeric")
+
+	w = acceptRequest("/error", "*/*", l)
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), `{"status":500,"message":"Internal Server Error"}`)
+
+	w = acceptRequest("/committed", "", l)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "partial")
+
+	var handled error
+
+	l.RegisterErrorHandler(func(c Context, err error) {
+		handled = err
+		c.Text(http.StatusTeapot, err.Error())
+	})
+
+	w = acceptRequest("/http-error", "", l)
+	Equal(t, w.Code, http.StatusTeapot)
+	Equal(t, w.Body.String(), "400 id must be numeric")
+	Equal(t, handled.(*HTTPError).Code, "invalid_id")
+
+	Equal(t, NewHTTPError(http.StatusNotFound, "", "").Message, "Not Found")
+}
+
+type testValidator struct{}
+
+func (testValidator) Validate(v interface{}) error {
+
+	u := v.(*struct{ Name, Email string })
+
+	var errs ValidationErrors
+
+	if u.Name == blank {
+		errs = append(errs, &FieldError{Field: "Name", Rule: "required", Message: "Name is required"})
+	}
+
+	if u.Email == blank {
+		errs = append(errs, &FieldError{Field: "Email", Rule: "required", Message: "Email is required"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func TestDecodeAndValidate(t *testing.T) {
+
+	validateRequest := func(body string, accept string, hf http.Handler) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(POST, "/users", strings.NewReader(body))
+		r.Header.Set(ContentType, ApplicationJSON)
+		r.Header.Set(Accept, accept)
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	l := New()
+	l.Post("/users", func(c Context) error {
+
+		u := new(struct{ Name, Email string })
+
+		if err := c.DecodeAndValidate(false, 16<<10, u); err != nil {
+			return err
+		}
+
+		return c.Text(http.StatusCreated, u.Name)
+	})
+
+	hf := l.Serve()
+
+	// no validator registered
+	w := validateRequest(`{}`, ApplicationJSON, hf)
+	Equal(t, w.Code, http.StatusCreated)
+
+	l.RegisterValidator(testValidator{})
+
+	w = validateRequest(`{"Name":"Joey","Email":"joey@example.com"}`, ApplicationJSON, hf)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "Joey")
+
+	w = validateRequest(`{"Name":"Joey"}`, ApplicationJSON, hf)
+	Equal(t, w.Code, 422)
+	Equal(t, w.Body.String(), `{"status":422,"code":"validation_failed","message":"Unprocessable Entity","fields":[{"field":"Email","rule":"required","message":"Email is required"}]}`)
+
+	w = validateRequest(`{}`, ApplicationXML, hf)
+	Equal(t, w.Code, 422)
+	Equal(t, w.Body.String(), xml.Header+`<error><status>422</status><code>validation_failed</code><message>Unprocessable Entity</message><field><name>Name</name><rule>required</rule><message>Name is required</message></field><field><name>Email</name><rule>required</rule><message>Email is required</message></field></error>`)
+
+	w = validateRequest(`{}`, TextPlain, hf)
+	Equal(t, w.Code, 422)
+	Equal(t, w.Body.String(), "Unprocessable Entity\nName: Name is required\nEmail: Email is required")
+
+	w = validateRequest(`{`, ApplicationJSON, hf)
+	Equal(t, w.Code, http.StatusInternalServerError)
+}
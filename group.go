@@ -1,8 +1,11 @@
 package lars
 
 import (
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/websocket"
 )
@@ -13,22 +16,86 @@ type IRouteGroup interface {
 	GroupWithNone(prefix string) IRouteGroup
 	GroupWithMore(prefix string, middleware ...Handler) IRouteGroup
 	Group(prefix string) IRouteGroup
+	Mount(prefix string, h Handler)
+	Register404(notFound ...Handler)
+	Register405(methodNotAllowed ...Handler)
 }
 
 // IRoutes interface for routes
 type IRoutes interface {
 	Use(...Handler)
-	Any(string, ...Handler)
-	Get(string, ...Handler)
-	Post(string, ...Handler)
-	Delete(string, ...Handler)
-	Patch(string, ...Handler)
-	Put(string, ...Handler)
-	Options(string, ...Handler)
-	Head(string, ...Handler)
-	Connect(string, ...Handler)
-	Trace(string, ...Handler)
-	WebSocket(websocket.Upgrader, string, Handler)
+	Any(string, ...Handler) IRoute
+	Get(string, ...Handler) IRoute
+	Post(string, ...Handler) IRoute
+	Delete(string, ...Handler) IRoute
+	Patch(string, ...Handler) IRoute
+	Put(string, ...Handler) IRoute
+	Options(string, ...Handler) IRoute
+	Head(string, ...Handler) IRoute
+	Connect(string, ...Handler) IRoute
+	Trace(string, ...Handler) IRoute
+	WebSocket(websocket.Upgrader, string, Handler) IRoute
+	Static(prefix string, root http.FileSystem, opts StaticOptions) IRoute
+	File(path string, filename string) IRoute
+}
+
+// IRoute interface for a single registered route
+type IRoute interface {
+	Name(name string)
+}
+
+// registeredRoute is returned when registering a route so
+// that it can be further configured, i.e. named.
+type registeredRoute struct {
+	path string
+	lars *LARS
+}
+
+var _ IRoute = &registeredRoute{}
+
+// noopRoute is returned for routes which failed to register
+// when collecting registration errors.
+type noopRoute struct{}
+
+var _ IRoute = noopRoute{}
+
+// Name does nothing as the route isn't registered
+func (noopRoute) Name(name string) {}
+
+// Name registers the route under the given name so that
+// its URL can be generated using LARS.URL.
+// NOTE: this will panic if the name is already in use.
+func (r *registeredRoute) Name(name string) {
+
+	if r.lars.collectErrors {
+		defer func() {
+			if rec := recover(); rec != nil {
+				r.lars.addRegistrationError(blank, r.path, blank, nil, rec)
+			}
+		}()
+	}
+
+	if name == blank {
+		panic("Route name must not be empty for path '" + r.path + "'")
+	}
+
+	if r.lars.namedRoutes == nil {
+		r.lars.namedRoutes = make(map[string]*namedRoute)
+	}
+
+	if existing, ok := r.lars.namedRoutes[name]; ok && existing.path != r.path {
+		panic("Route name '" + name + "' is already registered for path '" + existing.path + "'")
+	}
+
+	_, constraints := parseConstraints(r.path)
+
+	r.lars.namedRoutes[name] = &namedRoute{path: r.path, constraints: constraints}
+}
+
+// namedRoute contains the information needed to generate a named routes URL
+type namedRoute struct {
+	path        string
+	constraints []*paramConstraint
 }
 
 // routeGroup struct containing all fields and methods for use.
@@ -36,11 +103,49 @@ type routeGroup struct {
 	prefix     string
 	middleware HandlersChain
 	lars       *LARS
+	host       *hostRouter
+	parent     *routeGroup
+	inherited  int // number of the parent's middleware copied on creation
 }
 
 var _ IRouteGroup = &routeGroup{}
 
-func (g *routeGroup) handle(method string, path string, handlers []Handler) {
+func (g *routeGroup) handle(method string, path string, handlers []Handler) (route IRoute) {
+
+	var in *insertion
+	name := ""
+
+	trees := g.lars.trees
+	if g.host != nil {
+		trees = g.host.trees
+	}
+
+	if g.lars.collectErrors {
+
+		// insert into a copy of the root, copying each node before it's
+		// modified, so the original tree is kept on error
+		original := trees[method]
+		if original != nil {
+			trees[method] = original.copy()
+		}
+
+		in = new(insertion)
+
+		defer func() {
+
+			if r := recover(); r != nil {
+
+				if original == nil {
+					delete(trees, method)
+				} else {
+					trees[method] = original
+				}
+
+				g.lars.addRegistrationError(method, g.prefix+path, name, in.conflict, r)
+				route = noopRoute{}
+			}
+		}()
+	}
 
 	if len(handlers) == 0 {
 		panic("No handler mapped to path:" + path)
@@ -51,7 +156,6 @@ func (g *routeGroup) handle(method string, path string, handlers []Handler) {
 	}
 
 	chain := make(HandlersChain, len(handlers))
-	name := ""
 
 	for i, h := range handlers {
 
@@ -62,22 +166,28 @@ func (g *routeGroup) handle(method string, path string, handlers []Handler) {
 		}
 	}
 
-	tree := g.lars.trees[method]
+	tree := trees[method]
 	if tree == nil {
 		tree = new(node)
-		g.lars.trees[method] = tree
+		trees[method] = tree
 	}
 
 	combined := make(HandlersChain, len(g.middleware)+len(chain))
 	copy(combined, g.middleware)
 	copy(combined[len(g.middleware):], chain)
 
-	pCount := tree.add(g.prefix+path, name, combined)
+	pCount := tree.add(g.prefix+path, name, combined, in)
 	pCount++
 
+	if g.host != nil {
+		pCount += g.host.params
+	}
+
 	if pCount > g.lars.mostParams {
 		g.lars.mostParams = pCount
 	}
+
+	return &registeredRoute{path: g.prefix + path, lars: g.lars}
 }
 
 // Use adds a middleware handler to the group middleware chain.
@@ -88,82 +198,86 @@ func (g *routeGroup) Use(m ...Handler) {
 }
 
 // Connect adds a CONNECT route & handler to the router.
-func (g *routeGroup) Connect(path string, h ...Handler) {
-	g.handle(CONNECT, path, h)
+func (g *routeGroup) Connect(path string, h ...Handler) IRoute {
+	return g.handle(CONNECT, path, h)
 }
 
 // Delete adds a DELETE route & handler to the router.
-func (g *routeGroup) Delete(path string, h ...Handler) {
-	g.handle(DELETE, path, h)
+func (g *routeGroup) Delete(path string, h ...Handler) IRoute {
+	return g.handle(DELETE, path, h)
 }
 
 // Get adds a GET route & handler to the router.
-func (g *routeGroup) Get(path string, h ...Handler) {
-	g.handle(GET, path, h)
+func (g *routeGroup) Get(path string, h ...Handler) IRoute {
+	return g.handle(GET, path, h)
 }
 
 // Head adds a HEAD route & handler to the router.
-func (g *routeGroup) Head(path string, h ...Handler) {
-	g.handle(HEAD, path, h)
+func (g *routeGroup) Head(path string, h ...Handler) IRoute {
+	return g.handle(HEAD, path, h)
 }
 
 // Options adds an OPTIONS route & handler to the router.
-func (g *routeGroup) Options(path string, h ...Handler) {
-	g.handle(OPTIONS, path, h)
+func (g *routeGroup) Options(path string, h ...Handler) IRoute {
+	return g.handle(OPTIONS, path, h)
 }
 
 // Patch adds a PATCH route & handler to the router.
-func (g *routeGroup) Patch(path string, h ...Handler) {
-	g.handle(PATCH, path, h)
+func (g *routeGroup) Patch(path string, h ...Handler) IRoute {
+	return g.handle(PATCH, path, h)
 }
 
 // Post adds a POST route & handler to the router.
-func (g *routeGroup) Post(path string, h ...Handler) {
-	g.handle(POST, path, h)
+func (g *routeGroup) Post(path string, h ...Handler) IRoute {
+	return g.handle(POST, path, h)
 }
 
 // Put adds a PUT route & handler to the router.
-func (g *routeGroup) Put(path string, h ...Handler) {
-	g.handle(PUT, path, h)
+func (g *routeGroup) Put(path string, h ...Handler) IRoute {
+	return g.handle(PUT, path, h)
 }
 
 // Trace adds a TRACE route & handler to the router.
-func (g *routeGroup) Trace(path string, h ...Handler) {
-	g.handle(TRACE, path, h)
+func (g *routeGroup) Trace(path string, h ...Handler) IRoute {
+	return g.handle(TRACE, path, h)
 }
 
 // Handle allows for any method to be registered with the given
 // route & handler. Allows for non standard methods to be used
 // like CalDavs PROPFIND and so forth.
-func (g *routeGroup) Handle(method string, path string, h ...Handler) {
-	g.handle(method, path, h)
+func (g *routeGroup) Handle(method string, path string, h ...Handler) IRoute {
+	return g.handle(method, path, h)
 }
 
 // Any adds a route & handler to the router for all HTTP methods.
-func (g *routeGroup) Any(path string, h ...Handler) {
-	g.Connect(path, h...)
-	g.Delete(path, h...)
-	g.Get(path, h...)
-	g.Head(path, h...)
-	g.Options(path, h...)
-	g.Patch(path, h...)
-	g.Post(path, h...)
-	g.Put(path, h...)
-	g.Trace(path, h...)
+func (g *routeGroup) Any(path string, h ...Handler) IRoute {
+	return g.Match([]string{CONNECT, DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT, TRACE}, path, h...)
 }
 
 // Match adds a route & handler to the router for multiple HTTP methods provided.
-func (g *routeGroup) Match(methods []string, path string, h ...Handler) {
+// When collecting registration errors and any method fails the route can't be named.
+func (g *routeGroup) Match(methods []string, path string, h ...Handler) IRoute {
+
+	var failed bool
+
 	for _, m := range methods {
-		g.handle(m, path, h)
+		if _, ok := g.handle(m, path, h).(noopRoute); ok {
+			failed = true
+		}
+	}
+
+	if failed {
+		return noopRoute{}
 	}
+
+	return &registeredRoute{path: g.prefix + path, lars: g.lars}
 }
 
 // WebSocket adds a websocket route
-func (g *routeGroup) WebSocket(upgrader websocket.Upgrader, path string, h Handler) {
+func (g *routeGroup) WebSocket(upgrader websocket.Upgrader, path string, h Handler) IRoute {
 
 	handler := g.lars.wrapHandler(h)
-	g.Get(path, func(c Context) {
+	return g.Get(path, func(c Context) {
 
 		ctx := c.BaseContext()
 		var err error
@@ -178,11 +292,82 @@ func (g *routeGroup) WebSocket(upgrader websocket.Upgrader, path string, h Handl
 	}, handler)
 }
 
+// Mount mounts another *LARS instance, or any http.Handler, under the prefix for
+// all HTTP methods; the request path is stripped of the prefix before being
+// handled and the groups middleware is run first. When mounting a *LARS
+// instance any params from the prefix i.e. /orgs/:org/billing are propagated
+// to the mounted instances Context params and any redirects it issues, such as
+// for trailing slashes, retain the prefix.
+func (g *routeGroup) Mount(prefix string, h Handler) {
+
+	var serve func(w http.ResponseWriter, r *http.Request, params Params, mountPath string)
+
+	switch h := h.(type) {
+	case *LARS:
+		var once sync.Once
+
+		serve = func(w http.ResponseWriter, r *http.Request, params Params, mountPath string) {
+			once.Do(func() { h.Serve() })
+			h.serve(w, r, params, mountPath)
+		}
+
+	case http.Handler:
+		serve = func(w http.ResponseWriter, r *http.Request, _ Params, _ string) {
+			h.ServeHTTP(w, r)
+		}
+
+	default:
+		panic("Mount requires a *LARS instance or http.Handler to be mounted at prefix '" + g.prefix + prefix + "'")
+	}
+
+	mount := func(c Context) {
+
+		ctx := c.BaseContext()
+		params := ctx.params
+
+		// the wildcard, if present, is always the last param and
+		// contains the remaining path
+		if l := len(params); l > 0 && params[l-1].Key == WildcardParam {
+			params = params[:l-1]
+		}
+
+		rest := ctx.Param(WildcardParam)
+		if ctx.lars.useRawPath && !ctx.lars.unescapePathValues {
+			rest = unescapePath(rest)
+		}
+
+		// keep the escaped prefix so that redirects issued by the mounted
+		// router are relative to the parent
+		mountPath, raw := splitMountPath(ctx.request.URL.EscapedPath(), rest)
+
+		u := new(url.URL)
+		*u = *ctx.request.URL
+		u.Path = basePath + rest
+		u.RawPath = raw
+
+		r := new(http.Request)
+		*r = *ctx.request
+		r.URL = u
+
+		serve(ctx.response, r, params, ctx.mountPath+mountPath)
+	}
+
+	prefix = strings.TrimRight(prefix, basePath)
+
+	if g.prefix+prefix != blank {
+		g.Any(prefix, mount)
+	}
+
+	g.Any(prefix+"/*", mount)
+}
+
 // GroupWithNone creates a new sub router with specified prefix and no middleware attached.
 func (g *routeGroup) GroupWithNone(prefix string) IRouteGroup {
 	return &routeGroup{
 		prefix:     g.prefix + prefix,
 		lars:       g.lars,
+		host:       g.host,
+		parent:     g,
 		middleware: make(HandlersChain, 0),
 	}
 }
@@ -192,6 +377,9 @@ func (g *routeGroup) GroupWithMore(prefix string, middleware ...Handler) IRouteG
 	rg := &routeGroup{
 		prefix:     g.prefix + prefix,
 		lars:       g.lars,
+		host:       g.host,
+		parent:     g,
+		inherited:  len(g.middleware),
 		middleware: make(HandlersChain, len(g.middleware)),
 	}
 	copy(rg.middleware, g.middleware)
@@ -204,8 +392,185 @@ func (g *routeGroup) Group(prefix string) IRouteGroup {
 	rg := &routeGroup{
 		prefix:     g.prefix + prefix,
 		lars:       g.lars,
+		host:       g.host,
+		parent:     g,
+		inherited:  len(g.middleware),
 		middleware: make(HandlersChain, len(g.middleware)),
 	}
 	copy(rg.middleware, g.middleware)
 	return rg
 }
+
+// groupHandlers contains the 404 and 405 handlers registered on a group
+type groupHandlers struct {
+	group   *routeGroup
+	http404 HandlersChain
+	http405 HandlersChain
+
+	// the groups prefix without param constraints and chains
+	// including the groups middleware, built by Serve
+	prefix           string
+	notFound         HandlersChain
+	methodNotAllowed HandlersChain
+}
+
+// Register404 registers the not found handlers used for requests under the groups
+// prefix, run behind the groups middleware. The group with the longest matching
+// prefix is used, falling back to LARS.Register404 when none match.
+func (g *routeGroup) Register404(notFound ...Handler) {
+
+	chain := make(HandlersChain, len(notFound))
+
+	for i, h := range notFound {
+		chain[i] = g.lars.wrapHandler(h)
+	}
+
+	g.statusHandlers().http404 = chain
+}
+
+// Register405 registers the method not allowed handlers used for requests under the
+// groups prefix, run behind the groups middleware. The group with the longest matching
+// prefix is used, falling back to LARS.Register405 when none match.
+func (g *routeGroup) Register405(methodNotAllowed ...Handler) {
+
+	chain := make(HandlersChain, len(methodNotAllowed))
+
+	for i, h := range methodNotAllowed {
+		chain[i] = g.lars.wrapHandler(h)
+	}
+
+	g.statusHandlers().http405 = chain
+}
+
+// statusHandlers returns the groups 404 and 405 handlers, registering them if necessary
+func (g *routeGroup) statusHandlers() *groupHandlers {
+
+	for _, gh := range g.lars.groupHandlers {
+		if gh.group == g {
+			return gh
+		}
+	}
+
+	gh := &groupHandlers{group: g}
+	g.lars.groupHandlers = append(g.lars.groupHandlers, gh)
+
+	return gh
+}
+
+// build prepends the groups middleware to its handlers
+func (gh *groupHandlers) build() {
+
+	mw := gh.group.middleware
+
+	gh.prefix = stripConstraints(gh.group.prefix)
+	gh.notFound, gh.methodNotAllowed = nil, nil
+
+	if gh.http404 != nil {
+		gh.notFound = make(HandlersChain, len(mw)+len(gh.http404))
+		copy(gh.notFound, mw)
+		copy(gh.notFound[len(mw):], gh.http404)
+	}
+
+	if gh.http405 != nil {
+		gh.methodNotAllowed = make(HandlersChain, len(mw)+len(gh.http405))
+		copy(gh.methodNotAllowed, mw)
+		copy(gh.methodNotAllowed[len(mw):], gh.http405)
+	}
+}
+
+// matches returns if the path is under the groups prefix, matching each
+// segment with :param and * segments matching any value.
+func (gh *groupHandlers) matches(host *hostRouter, path string) bool {
+
+	if gh.group.host != host {
+		return false
+	}
+
+	return matchesPrefix(gh.prefix, path)
+}
+
+// sharedMiddleware returns the number of leading middleware both groups
+// have in common, following each group back to the one they were created from.
+func sharedMiddleware(a, b *routeGroup) int {
+
+	shared := make(map[*routeGroup]int)
+	n := len(a.middleware)
+
+	for g := a; g != nil; g = g.parent {
+		shared[g] = min(n, len(g.middleware))
+		n = min(n, g.inherited)
+	}
+
+	n = len(b.middleware)
+
+	for g := b; g != nil; g = g.parent {
+		if s, ok := shared[g]; ok {
+			return min(s, n)
+		}
+		n = min(n, g.inherited)
+	}
+
+	return 0
+}
+
+// matchesPrefix returns if the path is under the route prefix, stripped of
+// constraints, with :param and * segments matching any value.
+func matchesPrefix(prefix string, path string) bool {
+
+	var i, j int
+
+	for i < len(prefix) {
+
+		switch prefix[i] {
+
+		case paramByte:
+
+			// param name
+			for i < len(prefix) && prefix[i] != slashByte {
+				i++
+			}
+
+			// param value, which can't be empty
+			start := j
+			for j < len(path) && path[j] != slashByte {
+				j++
+			}
+
+			if j == start {
+				return false
+			}
+
+		case wildByte:
+			return true
+
+		default:
+
+			if j == len(path) || path[j] != prefix[i] {
+				return false
+			}
+
+			i++
+			j++
+		}
+	}
+
+	return j == len(path) || prefix == blank || prefix[len(prefix)-1] == slashByte || path[j] == slashByte
+}
+
+// findGroupHandlers returns the handlers of the group with the longest prefix matching
+// the path which has either 404, or 405, handlers registered; nil if none match.
+func (l *LARS) findGroupHandlers(host *hostRouter, path string, notFound bool) (found *groupHandlers) {
+
+	for _, gh := range l.groupHandlers {
+
+		if notFound && gh.notFound == nil || !notFound && gh.methodNotAllowed == nil {
+			continue
+		}
+
+		if gh.matches(host, path) && (found == nil || len(gh.prefix) > len(found.prefix)) {
+			found = gh
+		}
+	}
+
+	return
+}
@@ -143,3 +143,258 @@ func TestGrouplogic(t *testing.T) {
 	Equal(t, bb, 2)
 	Equal(t, cc, 1)
 }
+
+func TestMount(t *testing.T) {
+
+	var order string
+
+	billing := New()
+	billing.Use(func(c Context) {
+		order += "billing-"
+		c.Next()
+	})
+	billing.Get("/", func(c Context) {
+		c.Text(http.StatusOK, "billing home")
+	})
+	billing.Get("/invoices/:id", func(c Context) {
+		c.Text(http.StatusOK, c.Request().URL.Path+" "+c.Param("org")+" "+c.Param("id"))
+	})
+
+	l := New()
+	l.Use(func(c Context) {
+		order += "parent-"
+		c.Next()
+	})
+	l.Get("/", func(c Context) {
+		c.Text(http.StatusOK, "home")
+	})
+
+	l.Group("/orgs/:org").Mount("/billing/", billing)
+	l.Mount("/native", http.StripPrefix("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})))
+
+	code, body := request(GET, "/orgs/go-playground/billing/invoices/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/invoices/13 go-playground 13")
+	Equal(t, order, "parent-billing-")
+
+	code, body = request(GET, "/orgs/go-playground/billing", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "billing home")
+
+	code, body = request(GET, "/orgs/go-playground/billing/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "billing home")
+
+	code, _ = request(GET, "/orgs/go-playground/billing/unknown", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/native/static/css/main.css", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/css/main.css")
+
+	code, body = request(GET, "/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "home")
+
+	root := New()
+	root.Mount("/", billing)
+
+	code, body = request(GET, "/", root)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "billing home")
+
+	code, body = request(GET, "/invoices/1", root)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "/invoices/1  1")
+
+	// redirects issued by the mounted router keep the mount prefix
+	invoices := New()
+	invoices.SetRedirectFixedPath(true)
+	invoices.Get("/invoices/", func(c Context) {
+		c.Text(http.StatusOK, "invoices")
+	})
+	invoices.Static("/assets", http.Dir("_examples"), StaticOptions{Browse: true})
+
+	files := New()
+	files.SetUseRawPath(true)
+	files.Get("/files/:name", func(c Context) {
+		c.Text(http.StatusOK, c.Param("name"))
+	})
+	invoices.Mount("/nested", files)
+
+	parent := New()
+	parent.Group("/orgs/:org").Mount("/billing", invoices)
+
+	mountRequest := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		w := httptest.NewRecorder()
+		parent.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	w := mountRequest("/orgs/go%20playground/billing/invoices?page=2")
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/orgs/go%20playground/billing/invoices/?page=2")
+
+	w = mountRequest("/orgs/acme/billing/INVOICES/")
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/orgs/acme/billing/invoices/")
+
+	w = mountRequest("/orgs/acme/billing/assets")
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/orgs/acme/billing/assets/")
+
+	w = mountRequest("/orgs/acme/billing/nested/files/a%2Fb")
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "a/b")
+
+	PanicMatches(t, func() { l.Mount("/bad", func() {}) }, "Mount requires a *LARS instance or http.Handler to be mounted at prefix '/bad'")
+}
+
+func TestGroupStatusHandlers(t *testing.T) {
+
+	groupRequest := func(method string, path string, host string, hf http.Handler) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, path, nil)
+		if host != "" {
+			r.Host = host
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w
+	}
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Use(func(c Context) {
+		c.Response().Header().Add("X-Middleware", "global")
+		c.Next()
+	})
+	l.Get("/", basicHandler)
+	l.Get("/api/users", basicHandler)
+	l.Get("/api/v2/users", basicHandler)
+	l.Get("/apidocs", basicHandler)
+
+	api := l.GroupWithMore("/api", func(c Context) {
+		c.Response().Header().Add("X-Middleware", "api")
+		c.Next()
+	})
+	api.Register404(func(c Context) error {
+		return NewHTTPError(http.StatusNotFound, "not_found", "")
+	})
+	api.Register405(func(c Context) error {
+		return NewHTTPError(http.StatusMethodNotAllowed, "method_not_allowed", "")
+	})
+
+	v2 := api.Group("/v2/")
+	v2.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "v2 not found")
+	})
+
+	l.Host("admin.example.com").Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "admin not found")
+	})
+
+	l.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "<h1>Not Found</h1>")
+	})
+
+	orgs := l.Group("/orgs/:org<[a-z]+>")
+	orgs.Get("/users", basicHandler)
+	orgs.Static("/files", http.Dir("_examples"), StaticOptions{})
+	orgs.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "org not found")
+	})
+
+	l.Static("/api/assets", http.Dir("_examples"), StaticOptions{})
+
+	l.Group("/static").Static("", http.Dir("_examples"), StaticOptions{})
+
+	static := l.GroupWithNone("/static")
+	static.Use(func(c Context) {
+		c.Response().Header().Add("X-Middleware", "static")
+		c.Next()
+	})
+	static.Register404(func(c Context) {
+		c.Text(http.StatusNotFound, "static not found")
+	})
+
+	// groups handlers registered before Serve use middleware added to the group later
+	api.Use(func(c Context) {
+		c.Response().Header().Add("X-Middleware", "api-late")
+		c.Next()
+	})
+
+	hf := l.Serve()
+
+	w := groupRequest(GET, "/missing", "", hf)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "<h1>Not Found</h1>")
+	Equal(t, w.Header()["X-Middleware"], []string{"global"})
+
+	w = groupRequest(GET, "/api/missing", "", hf)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), `{"status":404,"code":"not_found","message":"Not Found"}`)
+	Equal(t, w.Header()["X-Middleware"], []string{"global", "api", "api-late"})
+
+	w = groupRequest(GET, "/api", "", hf)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+
+	// only matches whole path segments
+	w = groupRequest(GET, "/apix", "", hf)
+	Equal(t, w.Body.String(), "<h1>Not Found</h1>")
+
+	w = groupRequest(GET, "/api/v2/missing", "", hf)
+	Equal(t, w.Body.String(), "v2 not found")
+	Equal(t, w.Header()["X-Middleware"], []string{"global", "api"})
+
+	// v2 has no 405 handlers so the apis are used
+	w = groupRequest(POST, "/api/v2/users", "", hf)
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Body.String(), `{"status":405,"code":"method_not_allowed","message":"Method Not Allowed"}`)
+	Equal(t, w.Header().Get(Allow), GET)
+
+	w = groupRequest(POST, "/", "", hf)
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Body.String(), "")
+
+	w = groupRequest(GET, "/missing", "admin.example.com", hf)
+	Equal(t, w.Body.String(), "admin not found")
+
+	w = groupRequest(GET, "/api/missing", "admin.example.com", hf)
+	Equal(t, w.Body.String(), "admin not found")
+
+	// param segments match any value
+	w = groupRequest(GET, "/orgs/acme/missing", "", hf)
+	Equal(t, w.Body.String(), "org not found")
+	Equal(t, w.Header()["X-Middleware"], []string{"global"})
+
+	w = groupRequest(GET, "/orgs/", "", hf)
+	Equal(t, w.Body.String(), "<h1>Not Found</h1>")
+
+	// static files not found use the groups 404 handlers without
+	// running the middleware twice
+	w = groupRequest(GET, "/orgs/acme/files/missing.txt", "", hf)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "org not found")
+	Equal(t, w.Header()["X-Middleware"], []string{"global"})
+
+	w = groupRequest(GET, "/api/assets/missing.txt", "", hf)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), `{"status":404,"code":"not_found","message":"Not Found"}`)
+	Equal(t, w.Header()["X-Middleware"], []string{"global", "api", "api-late"})
+
+	// middleware not shared with the static routes group still runs
+	w = groupRequest(GET, "/static/missing.txt", "", hf)
+	Equal(t, w.Code, http.StatusNotFound)
+	Equal(t, w.Body.String(), "static not found")
+	Equal(t, w.Header()["X-Middleware"], []string{"global", "static"})
+
+	Equal(t, matchesPrefix("/orgs/:org/*", "/orgs/acme/any/thing"), true)
+	Equal(t, matchesPrefix("/orgs/:org", "/orgs/acme"), true)
+	Equal(t, matchesPrefix("/orgs/:org", "/orgs/acmex/x"), true)
+	Equal(t, matchesPrefix("/orgs/:org/users", "/orgs/acme/user"), false)
+	Equal(t, matchesPrefix("/orgs", "/orgsx"), false)
+}
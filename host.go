@@ -0,0 +1,167 @@
+package lars
+
+import (
+	"strings"
+)
+
+const hostLabelSeparator = "."
+
+// hostRouter contains the route trees for a single host pattern
+// i.e. api.example.com or :sub.example.com
+type hostRouter struct {
+	pattern string
+	labels  []string
+	params  uint8
+	trees   map[string]*node
+}
+
+func newHostRouter(pattern string) *hostRouter {
+
+	h := &hostRouter{
+		pattern: pattern,
+		labels:  strings.Split(pattern, hostLabelSeparator),
+		trees:   make(map[string]*node),
+	}
+
+	for _, label := range h.labels {
+
+		if len(label) == 0 {
+			panic("Bad host pattern '" + pattern + "' contains an empty label")
+		}
+
+		if label[0] == paramByte {
+
+			if len(label) < 2 {
+				panic("host params must be named with a non-empty name in host pattern '" + pattern + "'")
+			}
+
+			h.params++
+		}
+	}
+
+	return h
+}
+
+// match checks if the host matches the pattern appending any host params
+// to p, which must have the capacity for them.
+func (h *hostRouter) match(host string, p Params) (Params, bool) {
+
+	start := len(p)
+
+	for _, label := range h.labels {
+
+		if len(host) == 0 {
+			return p[:start], false
+		}
+
+		end := strings.Index(host, hostLabelSeparator)
+		if end == -1 {
+			end = len(host)
+		}
+
+		if label[0] == paramByte {
+			i := len(p)
+			p = p[:i+1] // expand slice within preallocated capacity
+			p[i].Key = label[1:]
+			p[i].Value = host[:end]
+		} else if !strings.EqualFold(label, host[:end]) {
+			return p[:start], false
+		}
+
+		if end == len(host) {
+			host = blank
+		} else {
+			host = host[end+1:]
+		}
+	}
+
+	if len(host) > 0 {
+		return p[:start], false
+	}
+
+	return p, true
+}
+
+// Host creates a new route group whose routes only match requests for the
+// given host pattern and retains existing middleware.
+// Patterns may be exact i.e. api.example.com or contain params, one per label,
+// i.e. :sub.example.com which are accessible using Context.Param("sub") just
+// like URL params. Exact hosts take precedence over patterns containing params
+// which are checked in the order registered, any request not matching a host
+// falls back to the routes registered directly on LARS and it's groups.
+// NOTE: the port, if any, is ignored when matching.
+func (l *LARS) Host(pattern string) IRouteGroup {
+
+	pattern = strings.ToLower(pattern)
+
+	var h *hostRouter
+
+	for _, existing := range l.hosts {
+		if existing.pattern == pattern {
+			h = existing
+			break
+		}
+	}
+
+	if h == nil {
+
+		h = newHostRouter(pattern)
+
+		if h.params == 0 {
+			// exact hosts take precedence so keep them at the front
+			i := 0
+			for i < len(l.hosts) && l.hosts[i].params == 0 {
+				i++
+			}
+
+			l.hosts = append(l.hosts, nil)
+			copy(l.hosts[i+1:], l.hosts[i:])
+			l.hosts[i] = h
+		} else {
+			l.hosts = append(l.hosts, h)
+		}
+
+		// the context must have the capacity for the host params, plus one
+		// for a wildcard, even when no routes are registered on the host
+		if h.params+1 > l.mostParams {
+			l.mostParams = h.params + 1
+		}
+	}
+
+	rg := &routeGroup{
+		lars:       l,
+		host:       h,
+		parent:     &l.routeGroup,
+		inherited:  len(l.middleware),
+		middleware: make(HandlersChain, len(l.middleware)),
+	}
+	copy(rg.middleware, l.middleware)
+
+	return rg
+}
+
+// findTrees returns the host router and route trees for the requests host, adding any
+// host params to the context, or nil and the default trees when no host matches.
+func (l *LARS) findTrees(c *Ctx) (*hostRouter, map[string]*node) {
+
+	if len(l.hosts) == 0 {
+		return nil, l.trees
+	}
+
+	host := c.request.Host
+
+	// strip port, taking care not to mangle IPv6 addresses
+	if i := strings.LastIndexByte(host, paramByte); i != -1 && strings.IndexByte(host[i:], ']') == -1 {
+		host = host[:i]
+	}
+
+	var ok bool
+
+	for _, h := range l.hosts {
+		if c.params, ok = h.match(host, c.params); ok {
+			return h, h.trees
+		}
+	}
+
+	return nil, l.trees
+}
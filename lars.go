@@ -3,7 +3,9 @@ package lars
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -62,12 +64,15 @@ const (
 	//---------
 
 	AcceptedLanguage   = "Accept-Language"
+	Accept             = "Accept"
 	AcceptEncoding     = "Accept-Encoding"
 	Authorization      = "Authorization"
+	CacheControl       = "Cache-Control"
 	ContentDisposition = "Content-Disposition"
 	ContentEncoding    = "Content-Encoding"
 	ContentLength      = "Content-Length"
 	ContentType        = "Content-Type"
+	ETag               = "ETag"
 	Location           = "Location"
 	Upgrade            = "Upgrade"
 	Vary               = "Vary"
@@ -116,19 +121,58 @@ type LARS struct {
 	routeGroup
 	trees map[string]*node
 
+	// hosts contains the route trees for each host registered using Host(),
+	// exact hosts first followed by those containing params.
+	hosts []*hostRouter
+
 	// function that gets called to create the context object... is total overridable using RegisterContext
 	contextFunc ContextFunc
 
 	pool sync.Pool
 
-	http404 HandlersChain // 404 Not Found
-	http405 HandlersChain // 405 Method Not Allowed
+	http404     HandlersChain // 404 Not Found
+	http405     HandlersChain // 405 Method Not Allowed
+	httpOPTIONS HandlersChain // automatic OPTIONS
 
 	automaticOPTIONS HandlersChain
 	notFound         HandlersChain
+	methodNotAllowed HandlersChain
+
+	// collectErrors, when enabled, records route registration errors
+	// in registrationErrors instead of panicking
+	collectErrors      bool
+	registrationErrors RegistrationErrors
+
+	// groupHandlers contains the 404 and 405 handlers registered on groups
+	groupHandlers []*groupHandlers
 
 	customHandlersFuncs customHandlers
 
+	// errorHandler handles errors returned from func(Context) error handlers
+	errorHandler ErrorHandlerFunc
+
+	// renderers used by Context.Negotiate and the order they're offered in
+	renderers     map[string]RendererFunc
+	rendererOrder []string
+	notAcceptable NotAcceptableFunc
+
+	// decoders used by Context.Decode by media type
+	decoders map[string]DecoderFunc
+
+	// validator run by Context.DecodeAndValidate
+	validator Validator
+
+	// max size of every request body, 0 for no limit
+	maxBodySize int64
+
+	// options for the JSON decoder used by Context.Decode
+	jsonUseNumber             bool
+	jsonDisallowUnknownFields bool
+
+	// namedRoutes maps a route name, registered using IRoute.Name, to it's full path
+	// for use in reverse routing
+	namedRoutes map[string]*namedRoute
+
 	// mostParams used to keep track of the most amount of
 	// params in any URL and this will set the default capacity
 	// of eachContext Params
@@ -141,6 +185,19 @@ type LARS struct {
 	// and 307 for all other request methods.
 	redirectTrailingSlash bool
 
+	// If enabled, the router tries to fix the current request path, if no
+	// handle is registered for it, by removing superfluous path elements
+	// like ../ or // using CleanPath and a case-insensitive lookup.
+	// For example /FOO and /..//Foo could be redirected to /foo.
+	redirectFixedPath bool
+
+	// If enabled, routes are matched against the escaped path, URL.EscapedPath(),
+	// so encoded slashes i.e. %2F can be part of a param value.
+	useRawPath bool
+
+	// If enabled, along with useRawPath, param values are unescaped.
+	unescapePathValues bool
+
 	// If enabled, the router checks if another method is allowed for the
 	// current route, if the current request can not be routed.
 	// If this is the case, the request is answered with 'Method Not Allowed'
@@ -158,11 +215,27 @@ type LARS struct {
 // and other information
 type RouteMap struct {
 	Depth   int    `json:"depth"`
+	Host    string `json:"host,omitempty"`
 	Path    string `json:"path"`
 	Method  string `json:"method"`
 	Handler string `json:"handler"`
 }
 
+// routeMaps sorts RouteMap's by host, path and then method
+type routeMaps []RouteMap
+
+func (r routeMaps) Len() int      { return len(r) }
+func (r routeMaps) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r routeMaps) Less(i, j int) bool {
+	if r[i].Host != r[j].Host {
+		return r[i].Host < r[j].Host
+	}
+	if r[i].Path == r[j].Path {
+		return r[i].Method < r[j].Method
+	}
+	return r[i].Path < r[j].Path
+}
+
 var (
 	default404Handler = func(c Context) {
 		http.Error(c.Response(), http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -194,11 +267,25 @@ func New() *LARS {
 		mostParams:                 0,
 		http404:                    []HandlerFunc{default404Handler},
 		http405:                    []HandlerFunc{methodNotAllowedHandler},
+		httpOPTIONS:                []HandlerFunc{automaticOPTIONSHandler},
 		redirectTrailingSlash:      true,
+		unescapePathValues:         true,
 		handleMethodNotAllowed:     false,
 		automaticallyHandleOPTIONS: false,
+		renderers:                  make(map[string]RendererFunc),
+		notAcceptable:              defaultNotAcceptableHandler,
+		decoders:                   make(map[string]DecoderFunc),
 	}
 
+	l.RegisterRenderer(ApplicationJSON, jsonRenderer)
+	l.RegisterRenderer(ApplicationXML, xmlRenderer)
+	l.RegisterRenderer(TextPlain, textRenderer)
+
+	l.RegisterDecoder(ApplicationJSON, jsonDecoder)
+	l.RegisterDecoder(ApplicationXML, xmlDecoder)
+	l.RegisterDecoder(ApplicationForm, formBodyDecoder)
+	l.RegisterDecoder(MultipartForm, multipartFormDecoder)
+
 	l.routeGroup.lars = l
 	l.pool.New = func() interface{} {
 
@@ -243,6 +330,12 @@ func (l *LARS) RegisterCustomHandler(customType interface{}, fn CustomHandlerFun
 	l.customHandlersFuncs[t] = fn
 }
 
+// RegisterErrorHandler registers the function called with the error returned
+// by any func(Context) error handler, by default DefaultErrorHandler is used.
+func (l *LARS) RegisterErrorHandler(fn ErrorHandlerFunc) {
+	l.errorHandler = fn
+}
+
 // RegisterContext registers a custom Context function for creation
 // and resetting of a global object passed per http request
 func (l *LARS) RegisterContext(fn ContextFunc) {
@@ -262,6 +355,124 @@ func (l *LARS) Register404(notFound ...Handler) {
 	l.http404 = chain
 }
 
+// Register405 alows for overriding of the method not allowed handler function,
+// used when SetHandle405MethodNotAllowed is enabled. The methods allowed for the
+// requests path have already been set in the Allow header when it's called.
+func (l *LARS) Register405(methodNotAllowed ...Handler) {
+
+	chain := make(HandlersChain, len(methodNotAllowed))
+
+	for i, h := range methodNotAllowed {
+		chain[i] = l.wrapHandler(h)
+	}
+
+	l.http405 = chain
+}
+
+// RegisterAutomaticOPTIONS alows for overriding of the automatic OPTIONS handler
+// function, used when SetAutomaticallyHandleOPTIONS is enabled. The methods allowed
+// for the requests path have already been set in the Allow header when it's called.
+func (l *LARS) RegisterAutomaticOPTIONS(options ...Handler) {
+
+	chain := make(HandlersChain, len(options))
+
+	for i, h := range options {
+		chain[i] = l.wrapHandler(h)
+	}
+
+	l.httpOPTIONS = chain
+}
+
+// Routes returns all registered routes, across every HTTP method and host,
+// sorted by host, path and then method.
+// useful for printing a route listing or verifying the routes a group registered.
+func (l *LARS) Routes() []RouteMap {
+
+	routes := make(routeMaps, 0)
+	routes = appendRoutes(routes, blank, l.trees)
+
+	for _, h := range l.hosts {
+		routes = appendRoutes(routes, h.pattern, h.trees)
+	}
+
+	sort.Sort(routes)
+
+	return routes
+}
+
+func appendRoutes(routes routeMaps, host string, trees map[string]*node) routeMaps {
+
+	for method, tree := range trees {
+		tree.walk(blank, 0, func(_ string, depth int, mc *methodChain) {
+			routes = append(routes, RouteMap{
+				Depth:   depth,
+				Host:    host,
+				Path:    mc.path,
+				Method:  method,
+				Handler: mc.handlerName,
+			})
+		})
+	}
+
+	return routes
+}
+
+// URL generates the path for the route registered under name, substituting
+// each :param and the *wildcard with the provided params values; the wildcard
+// value is provided using the WildcardParam key.
+// An error is returned if the name is unknown, a param is missing or a params
+// value does not satisfy it's constraint.
+func (l *LARS) URL(name string, params ...Param) (string, error) {
+
+	route, ok := l.namedRoutes[name]
+	if !ok {
+		return blank, fmt.Errorf("lars: no route registered with name '%s'", name)
+	}
+
+	path := route.path
+	buff := make([]byte, 0, len(path)+32)
+	param := 0
+
+	for i := 0; i < len(path); i++ {
+
+		c := path[i]
+		if c != paramByte && c != wildByte {
+			buff = append(buff, c)
+			continue
+		}
+
+		end := i + 1
+		for end < len(path) && path[end] != slashByte && path[end] != constraintStart {
+			end++
+		}
+
+		key := path[i+1 : end]
+		if c == wildByte {
+			key = WildcardParam
+		}
+
+		value, found := findParam(params, key)
+		if !found {
+			return blank, fmt.Errorf("lars: missing param '%s' for route '%s' with path '%s'", key, name, path)
+		}
+
+		if param < len(route.constraints) && route.constraints[param] != nil && !route.constraints[param].match(value) {
+			return blank, fmt.Errorf("lars: param '%s' value '%s' does not satisfy constraint '%s' for route '%s'", key, value, route.constraints[param].pattern, name)
+		}
+
+		if c == wildByte {
+			buff = append(buff, (&url.URL{Path: value}).EscapedPath()...)
+		} else {
+			buff = append(buff, strings.Replace(url.QueryEscape(value), "+", "%20", -1)...)
+		}
+
+		i = skipConstraint(path, end) - 1
+		param++
+	}
+
+	return string(buff), nil
+}
+
 // SetAutomaticallyHandleOPTIONS tells lars whether to
 // automatically handle OPTION requests; manually configured
 // OPTION handlers take precedence. default true
@@ -276,6 +487,30 @@ func (l *LARS) SetRedirectTrailingSlash(set bool) {
 	l.redirectTrailingSlash = set
 }
 
+// SetRedirectFixedPath tells lars whether to try and fix
+// a URL by cleaning it, using CleanPath, and finding it case-insensitively,
+// redirecting to the fixed path if found. default false
+func (l *LARS) SetRedirectFixedPath(set bool) {
+	l.redirectFixedPath = set
+}
+
+// SetUseRawPath tells lars whether to match routes against the requests
+// escaped path, URL.EscapedPath(), instead of URL.Path; allowing encoded
+// slashes i.e. /files/a%2Fb to match /files/:name. Only encoded slashes and
+// percents, %2F and %25, are left escaped when matching so static route
+// segments i.e. /café still match /caf%C3%A9. default false
+func (l *LARS) SetUseRawPath(set bool) {
+	l.useRawPath = set
+}
+
+// SetUnescapePathValues tells lars whether to unescape each param value
+// when SetUseRawPath is enabled, otherwise encoded slashes and percents
+// are left escaped. Param constraints see the values as they will be set.
+// default true
+func (l *LARS) SetUnescapePathValues(set bool) {
+	l.unescapePathValues = set
+}
+
 // SetHandle405MethodNotAllowed tells lars whether to
 // handle the http 405 Method Not Allowed status code
 func (l *LARS) SetHandle405MethodNotAllowed(set bool) {
@@ -293,10 +528,18 @@ func (l *LARS) Serve() http.Handler {
 	copy(l.notFound, l.middleware)
 	copy(l.notFound[len(l.middleware):], l.http404)
 
+	l.methodNotAllowed = make(HandlersChain, len(l.middleware)+len(l.http405))
+	copy(l.methodNotAllowed, l.middleware)
+	copy(l.methodNotAllowed[len(l.middleware):], l.http405)
+
+	for _, gh := range l.groupHandlers {
+		gh.build()
+	}
+
 	if l.automaticallyHandleOPTIONS {
-		l.automaticOPTIONS = make(HandlersChain, len(l.middleware)+1)
+		l.automaticOPTIONS = make(HandlersChain, len(l.middleware)+len(l.httpOPTIONS))
 		copy(l.automaticOPTIONS, l.middleware)
-		copy(l.automaticOPTIONS[len(l.middleware):], []HandlerFunc{automaticOPTIONSHandler})
+		copy(l.automaticOPTIONS[len(l.middleware):], l.httpOPTIONS)
 	}
 
 	return http.HandlerFunc(l.serveHTTP)
@@ -304,60 +547,82 @@ func (l *LARS) Serve() http.Handler {
 
 // Conforms to the http.Handler interface.
 func (l *LARS) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	l.serve(w, r, nil, blank)
+}
+
+// serve handles the request, params will be non-nil when mounted
+// under another LARS instance whose route contained params and
+// mountPath the escaped path prefix it's mounted under.
+func (l *LARS) serve(w http.ResponseWriter, r *http.Request, params Params, mountPath string) {
 
 	c := l.pool.Get().(*Ctx)
 
 	c.parent.RequestStart(w, r)
+	c.mountPath = mountPath
 
-	if root := l.trees[r.Method]; root != nil {
+	if l.maxBodySize > 0 && r.Body != nil {
+		r.Body = newMaxBytesReader(r.Body, l.maxBodySize)
+	}
 
-		if c.handlers, c.params, c.handlerName = root.find(r.URL.Path, c.params); c.handlers == nil {
+	if len(params) > 0 {
 
-			c.params = c.params[0:0]
+		if cap(c.params) < len(params)+int(l.mostParams) {
+			c.params = make(Params, 0, len(params)+int(l.mostParams))
+		}
 
-			if l.redirectTrailingSlash && len(r.URL.Path) > 1 {
+		c.params = append(c.params, params...)
+	}
 
-				// find again all lowercase
-				orig := r.URL.Path
-				lc := strings.ToLower(orig)
+	host, trees := l.findTrees(c)
+	baseParams := len(c.params)
 
-				if lc != r.URL.Path {
+	path := l.routingPath(r.URL)
+	unescape := l.useRawPath && l.unescapePathValues
 
-					if c.handlers, _, _ = root.find(lc, c.params); c.handlers != nil {
-						r.URL.Path = lc
-						c.handlers = l.redirect(r.Method, r.URL.String())
-						r.URL.Path = orig
-						goto END
-					}
-				}
+	if root := trees[r.Method]; root != nil {
+
+		if c.handlers, c.params, c.handlerName, c.routePath = root.find(path, c.params, unescape); c.handlers == nil {
 
-				if lc[len(lc)-1:] == basePath {
-					lc = lc[:len(lc)-1]
-				} else {
-					lc = lc + basePath
+			c.params = c.params[0:baseParams]
+
+			if l.redirectTrailingSlash && len(path) > 1 {
+
+				// try a case-insensitive lookup, also adding or removing the trailing slash
+				if fixed, found := root.findCaseInsensitive(path, true, unescape); found && string(fixed) != path {
+					c.handlers = l.redirect(r.Method, l.fixedURL(r.URL, mountPath, string(fixed)))
+					goto END
 				}
+			}
+
+			if l.redirectFixedPath {
 
-				if c.handlers, _, _ = root.find(lc, c.params); c.handlers != nil {
-					r.URL.Path = lc
-					c.handlers = l.redirect(r.Method, r.URL.String())
-					r.URL.Path = orig
+				if fixed, found := root.findCaseInsensitive(CleanPath(path), l.redirectTrailingSlash, unescape); found && string(fixed) != path {
+					c.handlers = l.redirect(r.Method, l.fixedURL(r.URL, mountPath, string(fixed)))
 					goto END
 				}
 			}
 
+		} else if unescape {
+
+			for i := baseParams; i < len(c.params); i++ {
+				c.params[i].Value = unescapePath(c.params[i].Value)
+			}
+
+			goto END
+
 		} else {
 			goto END
 		}
 	}
 
 	if l.automaticallyHandleOPTIONS && r.Method == OPTIONS {
-		l.getOptions(c)
+		l.getOptions(c, path, trees)
 		goto END
 	}
 
 	if l.handleMethodNotAllowed {
 
-		if l.checkMethodNotAllowed(c) {
+		if l.checkMethodNotAllowed(c, path, host, trees) {
 			goto END
 		}
 	}
@@ -365,6 +630,10 @@ func (l *LARS) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	// not found
 	c.handlers = l.notFound
 
+	if gh := l.findGroupHandlers(host, path, true); gh != nil {
+		c.handlers = gh.notFound
+	}
+
 END:
 
 	c.parent.Next()
@@ -373,11 +642,11 @@ END:
 	l.pool.Put(c)
 }
 
-func (l *LARS) getOptions(c *Ctx) {
+func (l *LARS) getOptions(c *Ctx, path string, trees map[string]*node) {
 
-	if c.request.URL.Path == "*" { // check server-wide OPTIONS
+	if path == "*" { // check server-wide OPTIONS
 
-		for m := range l.trees {
+		for m := range trees {
 
 			if m == OPTIONS {
 				continue
@@ -387,13 +656,13 @@ func (l *LARS) getOptions(c *Ctx) {
 		}
 
 	} else {
-		for m, tree := range l.trees {
+		for m, tree := range trees {
 
 			if m == c.request.Method || m == OPTIONS {
 				continue
 			}
 
-			if c.handlers, _, _ = tree.find(c.request.URL.Path, c.params); c.handlers != nil {
+			if c.handlers, _, _, _ = tree.find(path, c.params, l.useRawPath && l.unescapePathValues); c.handlers != nil {
 				c.response.Header().Add(Allow, m)
 			}
 		}
@@ -406,12 +675,12 @@ func (l *LARS) getOptions(c *Ctx) {
 	return
 }
 
-func (l *LARS) checkMethodNotAllowed(c *Ctx) (found bool) {
+func (l *LARS) checkMethodNotAllowed(c *Ctx, path string, host *hostRouter, trees map[string]*node) (found bool) {
 
-	for m, tree := range l.trees {
+	for m, tree := range trees {
 
 		if m != c.request.Method {
-			if c.handlers, _, _ = tree.find(c.request.URL.Path, c.params); c.handlers != nil {
+			if c.handlers, _, _, _ = tree.find(path, c.params, l.useRawPath && l.unescapePathValues); c.handlers != nil {
 				// add methods
 				c.response.Header().Add(Allow, m)
 				found = true
@@ -420,7 +689,12 @@ func (l *LARS) checkMethodNotAllowed(c *Ctx) (found bool) {
 	}
 
 	if found {
-		c.handlers = l.http405
+
+		c.handlers = l.methodNotAllowed
+
+		if gh := l.findGroupHandlers(host, path, false); gh != nil {
+			c.handlers = gh.methodNotAllowed
+		}
 	}
 
 	return
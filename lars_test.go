@@ -7,6 +7,8 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	. "gopkg.in/go-playground/assert.v1"
@@ -396,6 +398,171 @@ func TestUseAndGroup(t *testing.T) {
 	Equal(t, log, "")
 }
 
+func TestRoutes(t *testing.T) {
+
+	l := New()
+	l.Get("/", HandlerForName)
+	l.Get("/users/:id", HandlerForName)
+	l.Post("/users/:id", HandlerForName)
+	l.Get("/users/:id/profile", HandlerForName)
+
+	g := l.Group("/files")
+	g.Get("/*", HandlerForName)
+
+	routes := l.Routes()
+	Equal(t, len(routes), 5)
+
+	name := "github.com/go-playground/lars.HandlerForName"
+
+	Equal(t, routes[0], RouteMap{Depth: 0, Path: "/", Method: GET, Handler: name})
+	Equal(t, routes[1], RouteMap{Depth: 3, Path: "/files/*", Method: GET, Handler: name})
+	Equal(t, routes[2], RouteMap{Depth: 2, Path: "/users/:id", Method: GET, Handler: name})
+	Equal(t, routes[3], RouteMap{Depth: 1, Path: "/users/:id", Method: POST, Handler: name})
+	Equal(t, routes[4], RouteMap{Depth: 3, Path: "/users/:id/profile", Method: GET, Handler: name})
+
+	Equal(t, len(New().Routes()), 0)
+}
+
+func TestNamedRoutes(t *testing.T) {
+
+	l := New()
+	l.Get("/", basicHandler).Name("home")
+	l.Get("/users/:id", basicHandler).Name("user.show")
+	l.Any("/users/:id/contact-info/:cid", basicHandler).Name("user.contact")
+
+	g := l.Group("/static")
+	g.Get("/*", basicHandler).Name("static")
+
+	u, err := l.URL("home")
+	Equal(t, err, nil)
+	Equal(t, u, "/")
+
+	u, err = l.URL("user.show", Param{Key: "id", Value: "13"})
+	Equal(t, err, nil)
+	Equal(t, u, "/users/13")
+
+	u, err = l.URL("user.show", Param{Key: "id", Value: "a b/c"})
+	Equal(t, err, nil)
+	Equal(t, u, "/users/a%20b%2Fc")
+
+	u, err = l.URL("user.contact", Param{Key: "cid", Value: "2"}, Param{Key: "id", Value: "1"})
+	Equal(t, err, nil)
+	Equal(t, u, "/users/1/contact-info/2")
+
+	u, err = l.URL("static", Param{Key: WildcardParam, Value: "css/main file.css"})
+	Equal(t, err, nil)
+	Equal(t, u, "/static/css/main%20file.css")
+
+	_, err = l.URL("user.contact", Param{Key: "id", Value: "1"})
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "lars: missing param 'cid' for route 'user.contact' with path '/users/:id/contact-info/:cid'")
+
+	_, err = l.URL("unknown")
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "lars: no route registered with name 'unknown'")
+
+	l.Get("/posts/:id<int>", basicHandler).Name("post.show")
+
+	u, err = l.URL("post.show", Param{Key: "id", Value: "13"})
+	Equal(t, err, nil)
+	Equal(t, u, "/posts/13")
+
+	_, err = l.URL("post.show", Param{Key: "id", Value: "thirteen"})
+	NotEqual(t, err, nil)
+	Equal(t, err.Error(), "lars: param 'id' value 'thirteen' does not satisfy constraint 'int' for route 'post.show'")
+
+	PanicMatches(t, func() { l.Post("/users", basicHandler).Name("user.show") }, "Route name 'user.show' is already registered for path '/users/:id'")
+	PanicMatches(t, func() { l.Get("/articles", basicHandler).Name("") }, "Route name must not be empty for path '/articles'")
+}
+
+func TestHostRouting(t *testing.T) {
+
+	fn := func(name string) HandlerFunc {
+		return func(c Context) {
+			if _, err := c.Response().Write([]byte(name + c.Param("sub") + c.Param("id"))); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	hostRequest := func(method, host, path string, l *LARS) (int, string) {
+		r, _ := http.NewRequest(method, path, nil)
+		r.Host = host
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w.Code, w.Body.String()
+	}
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Get("/users/:id", fn("default"))
+
+	sub := l.Host(":sub.example.com")
+	sub.Get("/users/:id", fn("sub"))
+
+	api := l.Host("API.example.com")
+	api.Get("/users/:id", fn("api"))
+	api.Group("/v1").Post("/users/:id", fn("apiv1"))
+
+	admin := l.Host("admin.example.com")
+	admin.Get("/", fn("admin"))
+
+	code, body := hostRequest(GET, "example.com", "/users/1", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default1")
+
+	code, body = hostRequest(GET, "api.example.com:8080", "/users/2", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "api2")
+
+	code, body = hostRequest(POST, "api.example.com", "/v1/users/3", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "apiv13")
+
+	code, body = hostRequest(GET, "billing.example.com", "/users/4", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "subbilling4")
+
+	code, body = hostRequest(GET, "admin.example.com", "/", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "admin")
+
+	code, _ = hostRequest(GET, "admin.example.com", "/users/4", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = hostRequest(PUT, "api.example.com", "/users/2", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+
+	// too many labels for the param host falls back to default routes
+	code, body = hostRequest(GET, "a.b.example.com", "/users/5", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default5")
+
+	code, body = hostRequest(GET, "[::1]:8080", "/users/6", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "default6")
+
+	routes := l.Routes()
+	Equal(t, len(routes), 5)
+	Equal(t, routes[0].Host, "")
+	Equal(t, routes[1].Host, ":sub.example.com")
+	Equal(t, routes[2].Host, "admin.example.com")
+	Equal(t, routes[3].Host, "api.example.com")
+	Equal(t, routes[3].Path, "/users/:id")
+	Equal(t, routes[4].Path, "/v1/users/:id")
+
+	// more host params than any registered route
+	l2 := New()
+	l2.Get("/", fn("default"))
+	l2.Host(":a.:b.:c.example.com")
+
+	code, _ = hostRequest(GET, "x.y.z.example.com", "/users/7", l2)
+	Equal(t, code, http.StatusNotFound)
+
+	PanicMatches(t, func() { l.Host("api..example.com") }, "Bad host pattern 'api..example.com' contains an empty label")
+	PanicMatches(t, func() { l.Host(":.example.com") }, "host params must be named with a non-empty name in host pattern ':.example.com'")
+}
+
 func TestBadAdd(t *testing.T) {
 	fn := func(c Context) {
 		if _, err := c.Response().Write([]byte(c.Request().Method)); err != nil {
@@ -879,7 +1046,7 @@ func TestMethodNotAllowed(t *testing.T) {
 
 	r, _ = http.NewRequest(GET, "/home/", nil)
 	w = httptest.NewRecorder()
-	l2.serveHTTP(w, r)
+	l2.Serve().ServeHTTP(w, r)
 
 	Equal(t, w.Code, http.StatusMethodNotAllowed)
 
@@ -952,6 +1119,208 @@ func TestRedirect(t *testing.T) {
 	Equal(t, code, http.StatusNotFound)
 }
 
+func TestRedirectFixedPath(t *testing.T) {
+
+	redirectRequest := func(method string, path string, hf http.Handler) (int, string) {
+		r, _ := http.NewRequest(method, "/", nil)
+		r.URL.Path = path
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+		return w.Code, w.Header().Get(Location)
+	}
+
+	l := New()
+	l.Get("/users/:Name", basicHandler)
+	l.Get("/users/:Name/Profile", basicHandler)
+	l.Get("/files/*", basicHandler)
+	l.Get("/about/", basicHandler)
+	l.Get("/id/:id<int>", basicHandler)
+	l.Get("/Accounts/:id<int>", basicHandler)
+	l.Get("/Accounts/:id<int>/Files/*", basicHandler)
+	l.Post("/login", basicHandler)
+
+	hf := l.Serve()
+
+	// mixed case params are kept as is
+	code, loc := redirectRequest(GET, "/USERS/JoeyBloggs/profile", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/users/JoeyBloggs/Profile")
+
+	code, loc = redirectRequest(GET, "/Users/JoeyBloggs/PROFILE/", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/users/JoeyBloggs/Profile")
+
+	code, loc = redirectRequest(GET, "/FILES/Some/File.TXT", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/files/Some/File.TXT")
+
+	code, loc = redirectRequest(GET, "/ABOUT", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/about/")
+
+	code, loc = redirectRequest(POST, "/LOGIN", hf)
+	Equal(t, code, http.StatusPermanentRedirect)
+	Equal(t, loc, "/login")
+
+	// not cleaned unless enabled
+	code, _ = redirectRequest(GET, "/users/../about/", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	// constraints failing don't redirect to themselves
+	code, _ = redirectRequest(GET, "/id/abc", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	// nor to a case-insensitive match failing them
+	code, _ = redirectRequest(GET, "/accounts/abc", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = redirectRequest(GET, "/accounts/abc/", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = redirectRequest(GET, "/accounts/abc/files/a.txt", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	code, loc = redirectRequest(GET, "/accounts/13/", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/Accounts/13")
+
+	code, loc = redirectRequest(GET, "/accounts/13/files/a.txt", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/Accounts/13/Files/a.txt")
+
+	l.SetRedirectFixedPath(true)
+
+	code, loc = redirectRequest(GET, "/users/../about/", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/about/")
+
+	code, loc = redirectRequest(GET, "//users//Joey/./Profile", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/users/Joey/Profile")
+
+	code, loc = redirectRequest(GET, "/./Files/../ABOUT", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/about/")
+
+	code, _ = redirectRequest(GET, "/id/abc", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = redirectRequest(GET, "/missing/../other", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	l.SetRedirectTrailingSlash(false)
+
+	code, _ = redirectRequest(GET, "/ABOUT", hf)
+	Equal(t, code, http.StatusNotFound)
+
+	code, loc = redirectRequest(GET, "/ABOUT/", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/about/")
+
+	code, loc = redirectRequest(GET, "/x/../about/", hf)
+	Equal(t, code, http.StatusMovedPermanently)
+	Equal(t, loc, "/about/")
+}
+
+func TestUseRawPath(t *testing.T) {
+
+	paramsHandler := func(c Context) {
+		params := c.BaseContext().params
+		values := make([]string, 0, len(params))
+		for _, p := range params {
+			values = append(values, p.Key+"="+p.Value)
+		}
+		c.Text(http.StatusOK, strings.Join(values, ","))
+	}
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Get("/files/:name", paramsHandler)
+	l.Get("/files/:name/meta", paramsHandler)
+	l.Get("/objects/:bucket/*", paramsHandler)
+	l.Get("/café", paramsHandler)
+	l.Get("/a b/:name", paramsHandler)
+	l.Get("/paths/:name<[a-z/]+>", paramsHandler)
+
+	hf := l.Serve()
+
+	w := serveRequest(GET, "/files/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	l.SetUseRawPath(true)
+
+	w = serveRequest(GET, "/files/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name=a/b")
+
+	w = serveRequest(GET, "/files/a%2Fb+c%20d/meta", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name=a/b+c d")
+
+	w = serveRequest(GET, "/objects/my%2Fbucket/path/to%2Fkey", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "bucket=my/bucket,*wildcard=path/to/key")
+
+	w = serveRequest(GET, "/files/plain", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name=plain")
+
+	w = serveRequest(GET, "/FILES/a%2Fb/META", nil, hf)
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/files/a%2Fb/meta")
+
+	w = serveRequest(GET, "/caf%C3%A9", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+
+	w = serveRequest(GET, "/a%20b/c%2Fd%25", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name=c/d%")
+
+	w = serveRequest(GET, "/CAF%C3%A9", nil, hf)
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/caf%C3%A9")
+
+	w = serveRequest(GET, "/A%20B/c%2Fd%25", nil, hf)
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/a%20b/c%2Fd%25")
+
+	w = serveRequest(POST, "/files/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Header().Get(Allow), GET)
+
+	// constraints are checked against the unescaped param values
+	w = serveRequest(GET, "/paths/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name=a/b")
+
+	w = serveRequest(GET, "/PATHS/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/paths/a%2Fb")
+
+	w = serveRequest(POST, "/paths/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Header().Get(Allow), GET)
+
+	l.SetUnescapePathValues(false)
+
+	// or the escaped values when they are not unescaped
+	w = serveRequest(GET, "/paths/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveRequest(GET, "/files/a%2Fb", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "name=a%2Fb")
+
+	w = serveRequest(GET, "/objects/my%2Fbucket/path/to%2Fkey", nil, hf)
+	Equal(t, w.Body.String(), "bucket=my%2Fbucket,*wildcard=path/to%2Fkey")
+
+	w = serveRequest(GET, "/a%20b/c%2Fd%25%20e", nil, hf)
+	Equal(t, w.Body.String(), "name=c%2Fd%25 e")
+
+	Equal(t, unescapePath("a%2Fb+c"), "a/b+c")
+	Equal(t, unescapePath("a%zz"), "a%zz")
+}
+
 func TestAutomaticallyHandleOPTIONS(t *testing.T) {
 
 	l := New()
@@ -987,6 +1356,47 @@ func TestAutomaticallyHandleOPTIONS(t *testing.T) {
 	Equal(t, len(allow), 4)
 }
 
+func TestRegister405AndAutomaticOPTIONS(t *testing.T) {
+
+	var logged []string
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.SetAutomaticallyHandleOPTIONS(true)
+	l.Use(func(c Context) {
+		c.Next()
+		logged = append(logged, c.Request().Method+" "+strconv.Itoa(c.Response().Status()))
+	})
+	l.Register405(func(c Context) error {
+		return NewHTTPError(http.StatusMethodNotAllowed, "method_not_allowed", "allowed: "+strings.Join(c.Response().Header()[Allow], ","))
+	})
+	l.RegisterAutomaticOPTIONS(func(c Context) {
+		c.Response().Header().Set("X-Options", "custom")
+		c.Response().WriteHeader(http.StatusNoContent)
+	})
+	l.Get("/home", basicHandler)
+
+	hf := l.Serve()
+
+	r, _ := http.NewRequest(POST, "/home", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusMethodNotAllowed)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), `{"status":405,"code":"method_not_allowed","message":"allowed: GET"}`)
+
+	r, _ = http.NewRequest(OPTIONS, "/home", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header().Get("X-Options"), "custom")
+	Equal(t, w.Header()[Allow], []string{GET, OPTIONS})
+
+	Equal(t, logged, []string{"POST 405", "OPTIONS 204"})
+}
+
 type closeNotifyingRecorder struct {
 	*httptest.ResponseRecorder
 	closed chan bool
@@ -1011,6 +1421,16 @@ func request(method, path string, l *LARS) (int, string) {
 	return w.Code, w.Body.String()
 }
 
+func serveRequest(method string, path string, headers map[string]string, hf http.Handler) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest(method, path, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	return w
+}
+
 func requestMultiPart(method string, url string, l *LARS) (int, string) {
 
 	body := &bytes.Buffer{}
@@ -1316,3 +1736,112 @@ var githubAPI = []route{
 	//{"PATCH", "/user/keys/:id"},
 	{"DELETE", "/user/keys/:id"},
 }
+
+func TestCollectRegistrationErrors(t *testing.T) {
+
+	l := New()
+	l.SetCollectRegistrationErrors(true)
+
+	l.Get("/users/:id", routeHandler)
+	l.Get("/users/:name", conflictHandler)
+	l.Get("/users/:id", basicHandler)
+	l.Get("/files/*path", routeHandler)
+	l.Get("/files/list", conflictHandler).Name("list")
+	l.Get("/bad//path", basicHandler)
+	l.Post("/users/:id", routeHandler)
+	l.Get("/named", basicHandler).Name("users")
+	l.Get("/named2", basicHandler).Name("users")
+
+	l.Get("/users/:id", routeHandler).Name("user")
+
+	// conflicts with a wildcard without a handler report the first route under it
+	l.Get("/orgs/:org/repos", routeHandler)
+	l.Get("/orgs/:org/members", basicHandler)
+	l.Get("/orgs/acme/members", conflictHandler)
+
+	// routes matching multiple methods can't be named if any method fails
+	l.Match([]string{GET, PUT}, "/files/list", basicHandler).Name("match")
+
+	err := l.Validate()
+	NotEqual(t, err, nil)
+
+	errs, ok := err.(RegistrationErrors)
+	Equal(t, ok, true)
+	Equal(t, len(errs), 8)
+
+	Equal(t, errs[0].Method, GET)
+	Equal(t, errs[0].Path, "/users/:name")
+	MatchRegex(t, errs[0].HandlerName, "conflictHandler$")
+	Equal(t, errs[0].ConflictPath, "/users/:id")
+	MatchRegex(t, errs[0].ConflictHandlerName, "routeHandler$")
+	MatchRegex(t, errs[0].Error(), "^lars: GET /users/:name \\(.*conflictHandler\\) conflicts with /users/:id \\(.*routeHandler\\): .*':name'.*':id'")
+
+	Equal(t, errs[1].Path, "/users/:id")
+	Equal(t, errs[1].ConflictPath, "/users/:id")
+	MatchRegex(t, errs[1].Message, "handlers are already registered")
+
+	Equal(t, errs[2].Path, "/files/list")
+	Equal(t, errs[2].ConflictPath, "/files/*path")
+
+	Equal(t, errs[3].Path, "/bad//path")
+	Equal(t, errs[3].ConflictPath, "")
+	Equal(t, errs[3].Error(), "lars: GET /bad//path: Bad path '/bad//path' contains duplicate // at index:4")
+
+	Equal(t, errs[4].Method, "")
+	Equal(t, errs[4].Path, "/named2")
+	Equal(t, errs[4].Error(), "lars: /named2: Route name 'users' is already registered for path '/named'")
+
+	Equal(t, errs[5].Path, "/users/:id")
+	Equal(t, errs[5].ConflictPath, "/users/:id")
+
+	Equal(t, errs[6].Path, "/orgs/acme/members")
+	Equal(t, errs[6].ConflictPath, "/orgs/:org/repos")
+	MatchRegex(t, errs[6].ConflictHandlerName, "routeHandler$")
+
+	Equal(t, errs[7].Method, GET)
+	Equal(t, errs[7].Path, "/files/list")
+
+	Equal(t, strings.Count(err.Error(), "\n"), 7)
+
+	// failed routes can't be named
+	_, err = l.URL("list")
+	NotEqual(t, err, nil)
+
+	_, err = l.URL("user")
+	NotEqual(t, err, nil)
+
+	_, err = l.URL("match")
+	NotEqual(t, err, nil)
+
+	// failed registrations are skipped and leave existing routes intact
+	code, body := request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "route")
+
+	code, body = request(GET, "/files/list", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "route")
+
+	code, _ = request(POST, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusOK)
+
+	code, _ = request(GET, "/bad//path", l)
+	Equal(t, code, http.StatusNotFound)
+
+	l2 := New()
+	l2.SetCollectRegistrationErrors(true)
+	l2.Get("/", basicHandler)
+	Equal(t, l2.Validate(), nil)
+
+	l3 := New()
+	PanicMatches(t, func() { l3.Get("/bad//path", basicHandler) }, "Bad path '/bad//path' contains duplicate // at index:4")
+	Equal(t, l3.Validate(), nil)
+}
+
+func routeHandler(c Context) {
+	c.Response().Write([]byte("route"))
+}
+
+func conflictHandler(c Context) {
+	c.Response().Write([]byte("conflict"))
+}
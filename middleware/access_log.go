@@ -0,0 +1,302 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+// AccessLogFormat is the format access log entries are written in
+type AccessLogFormat uint8
+
+// Access log formats
+const (
+	// CommonLogFormat is the Apache Common Log Format
+	CommonLogFormat AccessLogFormat = iota
+
+	// CombinedLogFormat is the Apache Combined Log Format
+	CombinedLogFormat
+
+	// JSONLogFormat writes each entry as a JSON object on it's own line
+	JSONLogFormat
+
+	// LogfmtLogFormat writes each entry as logfmt key=value pairs
+	LogfmtLogFormat
+)
+
+const (
+	clfTimeFormat          = "02/Jan/2006:15:04:05 -0700"
+	defaultRequestIDHeader = "X-Request-Id"
+	dash                   = "-"
+)
+
+// AccessLogOptions contains the options used by the AccessLog middleware;
+// the zero value writes the Common Log Format to os.Stdout.
+type AccessLogOptions struct {
+
+	// Output is where entries are written, default os.Stdout.
+	// Writes are serialized so the writer need not be safe for concurrent use.
+	Output io.Writer
+
+	// Format is the format of each entry, default CommonLogFormat.
+	Format AccessLogFormat
+
+	// SampleRate is the fraction, between 0 and 1, of requests logged;
+	// 0 logs every request. Responses with a status >= 500 are always logged.
+	SampleRate float64
+
+	// ExcludePaths are request paths not logged i.e. health checks; a path
+	// ending in '*' excludes all paths beginning with what precedes it.
+	ExcludePaths []string
+
+	// RequestIDHeader is the request header containing the request ID,
+	// default X-Request-Id.
+	RequestIDHeader string
+}
+
+// AccessLogEntry contains the information logged for a single request
+type AccessLogEntry struct {
+	Time      time.Time     `json:"time"`
+	Method    string        `json:"method"`
+	Path      string        `json:"path"`
+	Proto     string        `json:"proto"`
+	Route     string        `json:"route,omitempty"`
+	Handler   string        `json:"handler,omitempty"`
+	Status    int           `json:"status"`
+	Size      int64         `json:"size"`
+	Latency   time.Duration `json:"latency_ns"`
+	ClientIP  string        `json:"client_ip"`
+	RequestID string        `json:"request_id,omitempty"`
+	User      string        `json:"user,omitempty"`
+	Referer   string        `json:"referer,omitempty"`
+	UserAgent string        `json:"user_agent,omitempty"`
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// AccessLog returns a middleware which writes an access log entry for each
+// request, in the configured format, once the rest of the chain has completed.
+func AccessLog(opts AccessLogOptions) lars.HandlerFunc {
+
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	if opts.RequestIDHeader == "" {
+		opts.RequestIDHeader = defaultRequestIDHeader
+	}
+
+	var format func(*bytes.Buffer, *AccessLogEntry)
+
+	switch opts.Format {
+	case CommonLogFormat:
+		format = formatCommon
+	case CombinedLogFormat:
+		format = formatCombined
+	case JSONLogFormat:
+		format = formatJSON
+	case LogfmtLogFormat:
+		format = formatLogfmt
+	default:
+		panic("unknown access log format: " + strconv.Itoa(int(opts.Format)))
+	}
+
+	var mu sync.Mutex
+
+	return func(c lars.Context) {
+
+		start := time.Now()
+
+		c.Next()
+
+		req := c.Request()
+		res := c.Response()
+
+		if isExcludedPath(opts.ExcludePaths, req.URL.Path) {
+			return
+		}
+
+		if opts.SampleRate > 0 && opts.SampleRate < 1 && res.Status() < 500 && rand.Float64() >= opts.SampleRate {
+			return
+		}
+
+		entry := &AccessLogEntry{
+			Time:      start,
+			Method:    req.Method,
+			Path:      req.URL.RequestURI(),
+			Proto:     req.Proto,
+			Route:     c.RoutePath(),
+			Handler:   c.HandlerName(),
+			Status:    res.Status(),
+			Size:      res.Size(),
+			Latency:   time.Since(start),
+			ClientIP:  c.ClientIP(),
+			RequestID: req.Header.Get(opts.RequestIDHeader),
+			Referer:   req.Referer(),
+			UserAgent: req.UserAgent(),
+		}
+
+		if user, _, ok := req.BasicAuth(); ok {
+			entry.User = user
+		}
+
+		buff := bufferPool.Get().(*bytes.Buffer)
+		buff.Reset()
+
+		format(buff, entry)
+
+		mu.Lock()
+		opts.Output.Write(buff.Bytes())
+		mu.Unlock()
+
+		bufferPool.Put(buff)
+	}
+}
+
+func isExcludedPath(excluded []string, path string) bool {
+
+	for _, e := range excluded {
+
+		if l := len(e); l > 0 && e[l-1] == '*' {
+			if strings.HasPrefix(path, e[:l-1]) {
+				return true
+			}
+		} else if e == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return dash
+	}
+	return s
+}
+
+// writeEscaped writes s escaping quotes, backslashes and non printable bytes
+// the same way Apache escapes log items.
+func writeEscaped(buff *bytes.Buffer, s string) {
+
+	const hex = "0123456789abcdef"
+
+	for i := 0; i < len(s); i++ {
+
+		switch c := s[i]; c {
+		case '"', '\\':
+			buff.WriteByte('\\')
+			buff.WriteByte(c)
+		case '\b':
+			buff.WriteString(`\b`)
+		case '\n':
+			buff.WriteString(`\n`)
+		case '\r':
+			buff.WriteString(`\r`)
+		case '\t':
+			buff.WriteString(`\t`)
+		case '\v':
+			buff.WriteString(`\v`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				buff.WriteString(`\x`)
+				buff.WriteByte(hex[c>>4])
+				buff.WriteByte(hex[c&0x0f])
+			} else {
+				buff.WriteByte(c)
+			}
+		}
+	}
+}
+
+func formatCommon(buff *bytes.Buffer, e *AccessLogEntry) {
+
+	writeEscaped(buff, orDash(e.ClientIP))
+	buff.WriteString(" - ")
+	// spaces would split the user into separate fields
+	writeEscaped(buff, strings.Replace(orDash(e.User), " ", "_", -1))
+	buff.WriteString(" [")
+	buff.WriteString(e.Time.Format(clfTimeFormat))
+	buff.WriteString("] \"")
+	writeEscaped(buff, e.Method)
+	buff.WriteByte(' ')
+	writeEscaped(buff, e.Path)
+	buff.WriteByte(' ')
+	writeEscaped(buff, e.Proto)
+	buff.WriteString("\" ")
+	buff.WriteString(strconv.Itoa(e.Status))
+	buff.WriteByte(' ')
+
+	if e.Size == 0 {
+		buff.WriteString(dash)
+	} else {
+		buff.WriteString(strconv.FormatInt(e.Size, 10))
+	}
+
+	buff.WriteByte('\n')
+}
+
+func formatCombined(buff *bytes.Buffer, e *AccessLogEntry) {
+
+	formatCommon(buff, e)
+	buff.Truncate(buff.Len() - 1) // remove newline
+
+	buff.WriteString(" \"")
+	writeEscaped(buff, orDash(e.Referer))
+	buff.WriteString("\" \"")
+	writeEscaped(buff, orDash(e.UserAgent))
+	buff.WriteString("\"\n")
+}
+
+func formatJSON(buff *bytes.Buffer, e *AccessLogEntry) {
+	// Encode appends a newline
+	json.NewEncoder(buff).Encode(e)
+}
+
+func formatLogfmt(buff *bytes.Buffer, e *AccessLogEntry) {
+
+	writeLogfmtPair(buff, "time", e.Time.Format(time.RFC3339))
+	writeLogfmtPair(buff, "method", e.Method)
+	writeLogfmtPair(buff, "path", e.Path)
+	writeLogfmtPair(buff, "proto", e.Proto)
+	writeLogfmtPair(buff, "route", e.Route)
+	writeLogfmtPair(buff, "handler", e.Handler)
+	writeLogfmtPair(buff, "status", strconv.Itoa(e.Status))
+	writeLogfmtPair(buff, "size", strconv.FormatInt(e.Size, 10))
+	writeLogfmtPair(buff, "latency", e.Latency.String())
+	writeLogfmtPair(buff, "client_ip", e.ClientIP)
+	writeLogfmtPair(buff, "request_id", e.RequestID)
+	writeLogfmtPair(buff, "user", e.User)
+	writeLogfmtPair(buff, "referer", e.Referer)
+	writeLogfmtPair(buff, "user_agent", e.UserAgent)
+
+	buff.Truncate(buff.Len() - 1) // remove trailing space
+	buff.WriteByte('\n')
+}
+
+func writeLogfmtPair(buff *bytes.Buffer, key string, value string) {
+
+	buff.WriteString(key)
+	buff.WriteByte('=')
+
+	if value == "" || strings.ContainsAny(value, " =\"\\\t\n") {
+		buff.WriteString(strconv.Quote(value))
+	} else {
+		buff.WriteString(value)
+	}
+
+	buff.WriteByte(' ')
+}
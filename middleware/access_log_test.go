@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+func UserHandler(c lars.Context) {
+	c.Text(http.StatusOK, "user "+c.Param("id"))
+}
+
+func accessLogRequest(l *lars.LARS, path string) {
+	r, _ := http.NewRequest(lars.GET, path, nil)
+	r.RemoteAddr = "10.0.0.1:3000"
+	r.Header.Set("X-Request-Id", "abc-123")
+	r.Header.Set("Referer", "http://example.com/")
+	r.Header.Set("User-Agent", "lars-test")
+	r.SetBasicAuth("joeybloggs", "secret")
+	l.Serve().ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestAccessLogFormats(t *testing.T) {
+
+	buff := new(bytes.Buffer)
+
+	newRouter := func(format AccessLogFormat) *lars.LARS {
+		buff.Reset()
+		l := lars.New()
+		l.Use(AccessLog(AccessLogOptions{Output: buff, Format: format}))
+		l.Get("/users/:id", UserHandler)
+		return l
+	}
+
+	accessLogRequest(newRouter(CommonLogFormat), "/users/13?a=b")
+	MatchRegex(t, buff.String(), `^10\.0\.0\.1 - joeybloggs \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "GET /users/13\?a=b HTTP/1\.1" 200 7\n$`)
+
+	accessLogRequest(newRouter(CombinedLogFormat), "/unknown")
+	MatchRegex(t, buff.String(), `^10\.0\.0\.1 - joeybloggs \[.+\] "GET /unknown HTTP/1\.1" 404 10 "http://example\.com/" "lars-test"\n$`)
+
+	// fields are escaped so entries can't be split or forged
+	l := newRouter(CombinedLogFormat)
+	r, _ := http.NewRequest(lars.GET, "/users/13", nil)
+	r.RemoteAddr = "10.0.0.1:3000"
+	r.Header.Set("Referer", `http://example.com/"\`)
+	r.Header.Set("User-Agent", "lars\ntest\x01\xff")
+	r.SetBasicAuth("joey bloggs", "secret")
+	l.Serve().ServeHTTP(httptest.NewRecorder(), r)
+	Equal(t, strings.SplitN(buff.String(), " [", 2)[0], "10.0.0.1 - joey_bloggs")
+	MatchRegex(t, buff.String(), `" 200 7 "http://example\.com/\\"\\\\" "lars\\ntest\\x01\\xff"\n$`)
+
+	accessLogRequest(newRouter(JSONLogFormat), "/users/13")
+
+	var entry AccessLogEntry
+	err := json.Unmarshal(buff.Bytes(), &entry)
+	Equal(t, err, nil)
+	Equal(t, strings.HasSuffix(buff.String(), "}\n"), true)
+	Equal(t, entry.Method, lars.GET)
+	Equal(t, entry.Path, "/users/13")
+	Equal(t, entry.Route, "/users/:id")
+	Equal(t, entry.Handler, "github.com/go-playground/lars/middleware.UserHandler")
+	Equal(t, entry.Status, http.StatusOK)
+	Equal(t, entry.Size, int64(7))
+	Equal(t, entry.ClientIP, "10.0.0.1")
+	Equal(t, entry.RequestID, "abc-123")
+
+	accessLogRequest(newRouter(LogfmtLogFormat), "/users/13")
+	MatchRegex(t, buff.String(), `^time=\S+ method=GET path=/users/13 proto=HTTP/1\.1 route=/users/:id handler=github\.com/go-playground/lars/middleware\.UserHandler status=200 size=7 latency=\S+ client_ip=10\.0\.0\.1 request_id=abc-123 user=joeybloggs referer=http://example\.com/ user_agent=lars-test\n$`)
+
+	PanicMatches(t, func() { AccessLog(AccessLogOptions{Format: 99}) }, "unknown access log format: 99")
+}
+
+func TestAccessLogFiltering(t *testing.T) {
+
+	buff := new(bytes.Buffer)
+
+	l := lars.New()
+	l.Use(AccessLog(AccessLogOptions{
+		Output:       buff,
+		ExcludePaths: []string{"/health", "/static/*"},
+	}))
+	l.Get("/health", UserHandler)
+	l.Get("/static/*", UserHandler)
+	l.Get("/users/:id", UserHandler)
+
+	accessLogRequest(l, "/health")
+	accessLogRequest(l, "/static/css/main.css")
+	Equal(t, buff.Len(), 0)
+
+	accessLogRequest(l, "/users/13")
+	NotEqual(t, buff.Len(), 0)
+
+	buff.Reset()
+
+	l = lars.New()
+	l.Use(AccessLog(AccessLogOptions{Output: buff, SampleRate: 0.0000001}))
+	l.Get("/users/:id", UserHandler)
+	l.Get("/error", func(c lars.Context) {
+		c.Text(http.StatusInternalServerError, "error")
+	})
+
+	accessLogRequest(l, "/users/13")
+	Equal(t, buff.Len(), 0)
+
+	accessLogRequest(l, "/error")
+	NotEqual(t, buff.Len(), 0)
+}
@@ -0,0 +1,432 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/lars"
+)
+
+// Content codings
+const (
+	Brotli  = "br"
+	Deflate = "deflate"
+	Zstd    = "zstd"
+)
+
+const defaultMinLength = 1024
+
+// Encoder compresses everything written to it writing the result to the
+// underlying writer; Encoders are pooled and reused by calling Reset.
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// EncoderFunc returns a new Encoder writing to w using the compression
+// level; a level of 0 must use the encoders default compression level.
+type EncoderFunc func(w io.Writer, level int) (Encoder, error)
+
+var encoders = map[string]EncoderFunc{
+	lars.Gzip: func(w io.Writer, level int) (Encoder, error) {
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	},
+	Deflate: func(w io.Writer, level int) (Encoder, error) {
+		if level == 0 {
+			level = zlib.DefaultCompression
+		}
+		return zlib.NewWriterLevel(w, level)
+	},
+}
+
+// defaultEncodings is the servers default preference order, encodings
+// not registered are ignored.
+var defaultEncodings = []string{Brotli, Zstd, lars.Gzip, Deflate}
+
+// DefaultExcludedContentTypes are the content types, already compressed,
+// not compressed by default; entries ending in '*' match by prefix.
+var DefaultExcludedContentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"video/*",
+	"audio/*",
+	"font/woff",
+	"font/woff2",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+}
+
+// RegisterEncoder registers, or replaces, the EncoderFunc for the content coding
+// i.e. "br" or "zstd" so that it can be negotiated by the Compress middleware.
+// NOTE: not safe for concurrent use, encoders should be registered at startup
+// before calling Compress.
+func RegisterEncoder(coding string, fn EncoderFunc) {
+	encoders[strings.ToLower(coding)] = fn
+}
+
+// CompressOptions contains the options used by the Compress middleware;
+// the zero value is a usable configuration.
+type CompressOptions struct {
+
+	// Level is the compression level passed to each encoder, 0 uses the encoders default.
+	Level int
+
+	// MinLength is the minimum response size, in bytes, to compress; responses
+	// are buffered until this size is reached. default 1024, -1 to compress all.
+	MinLength int
+
+	// Encodings are the content codings, in order of the servers preference, that can
+	// be negotiated; default br, zstd, gzip, deflate of those registered.
+	Encodings []string
+
+	// ContentTypes, when not empty, are the only response content types compressed;
+	// entries ending in '*' match by prefix i.e. "text/*".
+	ContentTypes []string
+
+	// ExcludedContentTypes are the response content types not compressed,
+	// default DefaultExcludedContentTypes; entries ending in '*' match by prefix.
+	ExcludedContentTypes []string
+}
+
+// compressor contains the validated options and encoder pools
+// shared by all requests of a Compress middleware.
+type compressor struct {
+	minLength int
+	encodings []string
+	pools     map[string]*sync.Pool
+	allowed   []string
+	excluded  []string
+}
+
+// Compress returns a middleware which compresses HTTP responses using the content
+// coding negotiated from the requests Accept-Encoding header, including q-values.
+// Responses smaller than MinLength, of an excluded content type or that already
+// have a Content-Encoding are written uncompressed.
+func Compress(opts CompressOptions) lars.HandlerFunc {
+	return newCompressor(opts).handler
+}
+
+func newCompressor(opts CompressOptions) *compressor {
+
+	cp := &compressor{
+		minLength: opts.MinLength,
+		pools:     make(map[string]*sync.Pool),
+		allowed:   opts.ContentTypes,
+		excluded:  opts.ExcludedContentTypes,
+	}
+
+	if cp.minLength == 0 {
+		cp.minLength = defaultMinLength
+	} else if cp.minLength < 0 {
+		cp.minLength = 0
+	}
+
+	if cp.excluded == nil {
+		cp.excluded = DefaultExcludedContentTypes
+	}
+
+	if opts.Encodings == nil {
+		cp.encodings = registeredEncodings()
+	} else {
+		cp.encodings = make([]string, len(opts.Encodings))
+		copy(cp.encodings, opts.Encodings)
+	}
+
+	for i, coding := range cp.encodings {
+
+		coding = strings.ToLower(coding)
+		cp.encodings[i] = coding
+
+		fn, ok := encoders[coding]
+		if !ok {
+			panic("no encoder registered for content coding: " + coding)
+		}
+
+		// test encoder creation, then don't have to each time one is created
+		// in the pool
+		if _, err := fn(ioutil.Discard, opts.Level); err != nil {
+			panic(err)
+		}
+
+		level := opts.Level
+
+		cp.pools[coding] = &sync.Pool{
+			New: func() interface{} {
+				e, _ := fn(ioutil.Discard, level)
+				return e
+			},
+		}
+	}
+
+	return cp
+}
+
+func registeredEncodings() []string {
+
+	encodings := make([]string, 0, len(encoders))
+	seen := make(map[string]bool, len(encoders))
+
+	for _, coding := range defaultEncodings {
+		if _, ok := encoders[coding]; ok {
+			encodings = append(encodings, coding)
+			seen[coding] = true
+		}
+	}
+
+	others := make([]string, 0)
+
+	for coding := range encoders {
+		if !seen[coding] {
+			others = append(others, coding)
+		}
+	}
+
+	sort.Strings(others)
+
+	return append(encodings, others...)
+}
+
+func (cp *compressor) handler(c lars.Context) {
+
+	res := c.Response()
+	res.Header().Add(lars.Vary, lars.AcceptEncoding)
+
+	coding := negotiateEncoding(c.Request().Header.Get(lars.AcceptEncoding), cp.encodings)
+	if coding == "" {
+		c.Next()
+		return
+	}
+
+	orig := res.Writer()
+	cw := &compressWriter{
+		ResponseWriter: orig,
+		compressor:     cp,
+		coding:         coding,
+	}
+
+	res.SetWriter(cw)
+
+	defer func() {
+		cw.close()
+		res.SetWriter(orig)
+	}()
+
+	c.Next()
+}
+
+// compressible returns whether the content type is allowed to be compressed
+func (cp *compressor) compressible(contentType string) bool {
+
+	if idx := strings.IndexByte(contentType, ';'); idx != -1 {
+		contentType = contentType[:idx]
+	}
+
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+
+	if matchesContentType(cp.excluded, contentType) {
+		return false
+	}
+
+	return len(cp.allowed) == 0 || matchesContentType(cp.allowed, contentType)
+}
+
+func matchesContentType(types []string, contentType string) bool {
+
+	for _, t := range types {
+
+		if l := len(t); l > 0 && t[l-1] == '*' {
+			if strings.HasPrefix(contentType, t[:l-1]) {
+				return true
+			}
+		} else if t == contentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding returns the content coding, from those offered in order of
+// preference, with the highest q-value in the Accept-Encoding header or blank
+// if none are acceptable.
+func negotiateEncoding(acceptEncoding string, offered []string) string {
+
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, v := range lars.ParseAccept(acceptEncoding) {
+
+		coding := v.Value
+
+		switch coding {
+		case "*":
+			wildcard = v.Q
+			continue
+		case "x-gzip":
+			coding = lars.Gzip
+		}
+
+		accepted[coding] = v.Q
+	}
+
+	var best string
+	var bestQ float64
+
+	for _, coding := range offered {
+
+		q, ok := accepted[coding]
+		if !ok {
+			if wildcard < 0 {
+				continue
+			}
+			q = wildcard
+		}
+
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+
+	return best
+}
+
+// compressWriter buffers the response until enough has been written to decide
+// whether to compress it, only then committing the headers.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *compressor
+	coding     string
+	encoder    Encoder
+	buff       []byte
+	status     int
+	decided    bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buff = append(w.buff, b...)
+
+	if len(w.buff) >= w.compressor.minLength {
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// decide determines whether to compress the response, writing the headers and
+// any buffered data; force ignores the minimum length i.e. when flushing.
+func (w *compressWriter) decide(force bool) (err error) {
+
+	w.decided = true
+
+	h := w.Header()
+
+	if len(w.buff) > 0 && h.Get(lars.ContentType) == "" {
+		h.Set(lars.ContentType, http.DetectContentType(w.buff))
+	}
+
+	if len(w.buff) > 0 && (force || len(w.buff) >= w.compressor.minLength) &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified &&
+		h.Get(lars.ContentEncoding) == "" && w.compressor.compressible(h.Get(lars.ContentType)) {
+
+		h.Set(lars.ContentEncoding, w.coding)
+		h.Del(lars.ContentLength)
+
+		w.encoder = w.compressor.pools[w.coding].Get().(Encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if len(w.buff) > 0 {
+		if w.encoder != nil {
+			_, err = w.encoder.Write(w.buff)
+		} else {
+			_, err = w.ResponseWriter.Write(w.buff)
+		}
+	}
+
+	w.buff = nil
+
+	return
+}
+
+// close writes any buffered data and completes the compressed stream
+func (w *compressWriter) close() {
+
+	if !w.decided {
+		w.decide(false)
+	}
+
+	if w.encoder != nil {
+		w.encoder.Close()
+		w.encoder.Reset(ioutil.Discard)
+		w.compressor.pools[w.coding].Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+func (w *compressWriter) Flush() {
+
+	if !w.decided {
+		w.decide(true)
+	}
+
+	if w.encoder != nil {
+		w.encoder.Flush()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *compressWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+
+	offered := []string{Brotli, lars.Gzip, Deflate}
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"gzip", lars.Gzip},
+		{"x-gzip", lars.Gzip},
+		{"deflate, gzip", lars.Gzip},
+		{"gzip;q=0.5, deflate", Deflate},
+		{"GZIP;Q=0.8, br;q=0.9", Brotli},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"*", Brotli},
+		{"*;q=0.1, gzip;q=0.5", lars.Gzip},
+		{"br;q=0, *", lars.Gzip},
+		{"identity", ""},
+		{"gzip;q=abc", ""},
+		{"compress, sdch", ""},
+	}
+
+	for _, tt := range tests {
+		Equal(t, negotiateEncoding(tt.header, offered), tt.expected)
+	}
+}
+
+func TestCompress(t *testing.T) {
+
+	large := strings.Repeat("lars ", 500)
+
+	l := lars.New()
+	l.Use(Compress(CompressOptions{}))
+	l.Get("/large", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentLength, "2500")
+		c.Text(http.StatusOK, large)
+	})
+	l.Get("/chunks", func(c lars.Context) {
+		for i := 0; i < 500; i++ {
+			c.Response().Write([]byte("lars "))
+		}
+	})
+	l.Get("/small", func(c lars.Context) {
+		c.Text(http.StatusCreated, "small")
+	})
+	l.Get("/image", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentType, "image/png")
+		c.Response().Write([]byte(large))
+	})
+	l.Get("/encoded", func(c lars.Context) {
+		c.Response().Header().Set(lars.ContentEncoding, "custom")
+		c.Response().Write([]byte(large))
+	})
+	l.Get("/empty", func(c lars.Context) {
+		c.Response().WriteHeader(http.StatusNoContent)
+	})
+
+	w := serveRequest(l, lars.GET, "/large", map[string]string{lars.AcceptEncoding: "gzip;q=0.5, deflate"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), Deflate)
+	Equal(t, w.Header().Get(lars.ContentLength), "")
+	Equal(t, w.Header().Get(lars.Vary), lars.AcceptEncoding)
+	Equal(t, w.Header().Get(lars.ContentType), lars.TextPlainCharsetUTF8)
+
+	zr, err := zlib.NewReader(w.Body)
+	Equal(t, err, nil)
+	b, err := ioutil.ReadAll(zr)
+	Equal(t, err, nil)
+	Equal(t, string(b), large)
+
+	w = serveRequest(l, lars.GET, "/chunks", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), lars.Gzip)
+	Equal(t, w.Header().Get(lars.ContentType), lars.TextPlainCharsetUTF8)
+
+	gr, err := gzip.NewReader(w.Body)
+	Equal(t, err, nil)
+	b, err = ioutil.ReadAll(gr)
+	Equal(t, err, nil)
+	Equal(t, string(b), large)
+
+	w = serveRequest(l, lars.GET, "/small", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Body.String(), "small")
+
+	w = serveRequest(l, lars.GET, "/image", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Body.String(), large)
+
+	w = serveRequest(l, lars.GET, "/encoded", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Header().Get(lars.ContentEncoding), "custom")
+	Equal(t, w.Body.String(), large)
+
+	w = serveRequest(l, lars.GET, "/empty", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Body.Len(), 0)
+
+	w = serveRequest(l, lars.GET, "/large", map[string]string{lars.AcceptEncoding: ""})
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Body.String(), large)
+}
+
+func TestCompressRegisterEncoder(t *testing.T) {
+
+	RegisterEncoder("X-Test", func(w io.Writer, level int) (Encoder, error) {
+		return flate.NewWriter(w, level)
+	})
+	defer delete(encoders, "x-test")
+
+	Equal(t, registeredEncodings(), []string{lars.Gzip, Deflate, "x-test"})
+
+	l := lars.New()
+	l.Use(Compress(CompressOptions{MinLength: -1, Encodings: []string{"x-test", lars.Gzip}}))
+	l.Get("/flush", func(c lars.Context) {
+		c.Response().Write([]byte("a"))
+		c.Response().Flush()
+		c.Response().Write([]byte("b"))
+	})
+
+	w := serveRequest(l, lars.GET, "/flush", map[string]string{lars.AcceptEncoding: "gzip, x-test"})
+	Equal(t, w.Header().Get(lars.ContentEncoding), "x-test")
+	Equal(t, w.Flushed, true)
+
+	b, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(w.Body.Bytes())))
+	Equal(t, err, nil)
+	Equal(t, string(b), "ab")
+
+	PanicMatches(t, func() { Compress(CompressOptions{Encodings: []string{"unknown"}}) }, "no encoder registered for content coding: unknown")
+	PanicMatches(t, func() { Compress(CompressOptions{Level: 999}) }, "gzip: invalid compression level: 999")
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/lars"
+)
+
+// CORS headers
+const (
+	AccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	AccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	AccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	AccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	AccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	AccessControlMaxAge           = "Access-Control-Max-Age"
+	AccessControlRequestMethod    = "Access-Control-Request-Method"
+	AccessControlRequestHeaders   = "Access-Control-Request-Headers"
+)
+
+// DefaultCORSAllowMethods are the methods allowed by preflight requests when
+// neither the router, via automatic OPTIONS handling, or CORSConfig provide them.
+var DefaultCORSAllowMethods = []string{lars.GET, lars.HEAD, lars.POST, lars.PUT, lars.PATCH, lars.DELETE}
+
+// CORSConfig contains the configuration used by the CORS middleware
+type CORSConfig struct {
+
+	// AllowOrigins are the allowed origins; "*" allows any origin and a single '*'
+	// within an origin matches any subdomain i.e. "https://*.example.com".
+	// Matching is case insensitive.
+	AllowOrigins []string
+
+	// AllowOriginPatterns are regular expressions, matched against the whole
+	// origin, of additional allowed origins.
+	AllowOriginPatterns []string
+
+	// AllowMethods are the methods returned to preflight requests when the router
+	// has not already computed the Allow header; enable automatic OPTIONS handling,
+	// using LARS.SetAutomaticallyHandleOPTIONS, to have the methods actually
+	// registered for the requests path returned. default DefaultCORSAllowMethods.
+	AllowMethods []string
+
+	// AllowHeaders are the request headers allowed, when empty the
+	// preflight requests Access-Control-Request-Headers are allowed.
+	AllowHeaders []string
+
+	// ExposeHeaders are the response headers the client is allowed to access.
+	ExposeHeaders []string
+
+	// AllowCredentials indicates whether the request can include user credentials;
+	// the requests origin is returned instead of "*" when enabled.
+	AllowCredentials bool
+
+	// MaxAge is the number of seconds a preflight response can be cached, 0 omits it.
+	MaxAge int
+}
+
+type cors struct {
+	allowAll         bool
+	origins          []string
+	wildcards        [][2]string
+	patterns         []*regexp.Regexp
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	maxAge           string
+	allowCredentials bool
+}
+
+// CORS returns a middleware which handles Cross-Origin Resource Sharing,
+// responding to preflight requests and adding the CORS headers to actual
+// requests from allowed origins.
+func CORS(config CORSConfig) lars.HandlerFunc {
+
+	cr := &cors{
+		allowHeaders:     strings.Join(config.AllowHeaders, ", "),
+		exposeHeaders:    strings.Join(config.ExposeHeaders, ", "),
+		allowCredentials: config.AllowCredentials,
+	}
+
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = DefaultCORSAllowMethods
+	}
+
+	cr.allowMethods = strings.Join(config.AllowMethods, ", ")
+
+	if config.MaxAge > 0 {
+		cr.maxAge = strconv.Itoa(config.MaxAge)
+	}
+
+	for _, origin := range config.AllowOrigins {
+
+		origin = strings.ToLower(origin)
+
+		if origin == "*" {
+			cr.allowAll = true
+		} else if idx := strings.IndexByte(origin, '*'); idx != -1 {
+			cr.wildcards = append(cr.wildcards, [2]string{origin[:idx], origin[idx+1:]})
+		} else {
+			cr.origins = append(cr.origins, origin)
+		}
+	}
+
+	for _, pattern := range config.AllowOriginPatterns {
+		cr.patterns = append(cr.patterns, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+
+	return cr.handler
+}
+
+func (cr *cors) allowedOrigin(origin string) bool {
+
+	if cr.allowAll {
+		return true
+	}
+
+	lower := strings.ToLower(origin)
+
+	for _, o := range cr.origins {
+		if o == lower {
+			return true
+		}
+	}
+
+	for _, w := range cr.wildcards {
+		if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+
+	for _, re := range cr.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cr *cors) handler(c lars.Context) {
+
+	req := c.Request()
+	h := c.Response().Header()
+	origin := req.Header.Get(lars.Origin)
+
+	h.Add(lars.Vary, lars.Origin)
+
+	if origin == "" || !cr.allowedOrigin(origin) {
+		c.Next()
+		return
+	}
+
+	if cr.allowAll && !cr.allowCredentials {
+		h.Set(AccessControlAllowOrigin, "*")
+	} else {
+		h.Set(AccessControlAllowOrigin, origin)
+	}
+
+	if cr.allowCredentials {
+		h.Set(AccessControlAllowCredentials, "true")
+	}
+
+	// actual request
+	if req.Method != lars.OPTIONS || req.Header.Get(AccessControlRequestMethod) == "" {
+
+		if cr.exposeHeaders != "" {
+			h.Set(AccessControlExposeHeaders, cr.exposeHeaders)
+		}
+
+		c.Next()
+		return
+	}
+
+	// preflight request
+	h.Add(lars.Vary, AccessControlRequestMethod)
+	h.Add(lars.Vary, AccessControlRequestHeaders)
+
+	// the Allow header is computed by the router, for the requests path,
+	// when automatic OPTIONS handling is enabled.
+	if allow := h[lars.Allow]; len(allow) > 0 {
+		h.Set(AccessControlAllowMethods, strings.Join(allow, ", "))
+	} else {
+		h.Set(AccessControlAllowMethods, cr.allowMethods)
+	}
+
+	if cr.allowHeaders != "" {
+		h.Set(AccessControlAllowHeaders, cr.allowHeaders)
+	} else if headers := req.Header.Get(AccessControlRequestHeaders); headers != "" {
+		h.Set(AccessControlAllowHeaders, headers)
+	}
+
+	if cr.maxAge != "" {
+		h.Set(AccessControlMaxAge, cr.maxAge)
+	}
+
+	c.Response().WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+func TestCORSPreflight(t *testing.T) {
+
+	l := lars.New()
+	l.SetAutomaticallyHandleOPTIONS(true)
+	l.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}))
+	l.Get("/users", basicHandler)
+	l.Post("/users", basicHandler)
+	l.Delete("/users/:id", basicHandler)
+
+	w := serveRequest(l, lars.OPTIONS, "/users", map[string]string{
+		lars.Origin:                 "https://example.com",
+		AccessControlRequestMethod:  lars.POST,
+		AccessControlRequestHeaders: "X-Tenant, Content-Type",
+	})
+
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "https://example.com")
+	Equal(t, w.Header().Get(AccessControlAllowCredentials), "true")
+	Equal(t, w.Header().Get(AccessControlAllowHeaders), "X-Tenant, Content-Type")
+	Equal(t, w.Header().Get(AccessControlMaxAge), "600")
+	Equal(t, w.Header()[lars.Vary], []string{lars.Origin, AccessControlRequestMethod, AccessControlRequestHeaders})
+
+	methods := strings.Split(w.Header().Get(AccessControlAllowMethods), ", ")
+	sort.Strings(methods)
+	Equal(t, methods, []string{lars.GET, lars.OPTIONS, lars.POST})
+
+	w = serveRequest(l, lars.OPTIONS, "/users/13", map[string]string{
+		lars.Origin:                "https://api.example.org",
+		AccessControlRequestMethod: lars.DELETE,
+	})
+
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "https://api.example.org")
+	Equal(t, w.Header().Get(AccessControlAllowHeaders), "")
+
+	methods = strings.Split(w.Header().Get(AccessControlAllowMethods), ", ")
+	sort.Strings(methods)
+	Equal(t, methods, []string{lars.DELETE, lars.OPTIONS})
+
+	// disallowed origin
+	w = serveRequest(l, lars.OPTIONS, "/users", map[string]string{
+		lars.Origin:                "https://example.org",
+		AccessControlRequestMethod: lars.POST,
+	})
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "")
+	Equal(t, w.Header().Get(lars.Vary), lars.Origin)
+
+	// without automatic OPTIONS the configured methods are used
+	l2 := lars.New()
+	l2.Use(CORS(CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowHeaders: []string{"X-Tenant"},
+	}))
+	l2.Post("/users", basicHandler)
+
+	w = serveRequest(l2, lars.OPTIONS, "/users", map[string]string{
+		lars.Origin:                "https://example.com",
+		AccessControlRequestMethod: lars.POST,
+	})
+
+	Equal(t, w.Code, http.StatusNoContent)
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "*")
+	Equal(t, w.Header().Get(AccessControlAllowMethods), "GET, HEAD, POST, PUT, PATCH, DELETE")
+	Equal(t, w.Header().Get(AccessControlAllowHeaders), "X-Tenant")
+	Equal(t, w.Header().Get(AccessControlAllowCredentials), "")
+	Equal(t, w.Header().Get(AccessControlMaxAge), "")
+}
+
+func TestCORSActualRequest(t *testing.T) {
+
+	l := lars.New()
+	l.Use(CORS(CORSConfig{
+		AllowOriginPatterns: []string{`https://[a-z]+\.example\.com`},
+		ExposeHeaders:       []string{"X-Total", "X-Page"},
+	}))
+	l.Get("/users", func(c lars.Context) {
+		c.Text(http.StatusOK, "users")
+	})
+
+	w := serveRequest(l, lars.GET, "/users", map[string]string{lars.Origin: "https://api.example.com"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "users")
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "https://api.example.com")
+	Equal(t, w.Header().Get(AccessControlExposeHeaders), "X-Total, X-Page")
+	Equal(t, w.Header().Get(AccessControlAllowMethods), "")
+	Equal(t, w.Header().Get(lars.Vary), lars.Origin)
+
+	w = serveRequest(l, lars.GET, "/users", map[string]string{lars.Origin: "https://api.example.com.evil.com"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "")
+
+	w = serveRequest(l, lars.GET, "/users", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(AccessControlAllowOrigin), "")
+
+	PanicMatches(t, func() { CORS(CORSConfig{AllowOriginPatterns: []string{"[a-z"}}) }, "regexp: Compile(`^(?:[a-z)$`): error parsing regexp: missing closing ]: `[a-z)$`")
+}
+
+var basicHandler = func(lars.Context) {}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/lars"
+)
+
+const defaultETagMaxSize = 1 << 20
+
+// ETagOptions contains the options used by the ETag middleware
+type ETagOptions struct {
+
+	// Weak generates weak ETags, prefixed with W/, instead of strong ones.
+	Weak bool
+
+	// MaxSize is the max number of bytes of a response buffered to generate its
+	// ETag, larger responses are streamed without one. 0 uses the default of
+	// 1MB and -1 buffers the whole response.
+	MaxSize int
+
+	// Generate returns the ETag, including quotes, of a response body.
+	// default lars.NewETag
+	Generate func(b []byte) string
+}
+
+// ETag buffers GET and HEAD responses with a 200 OK status in order to set an ETag,
+// generated from the body unless the handler has set one, and answer conditional
+// requests with a 304 Not Modified or 412 Precondition Failed, using the Last-Modified
+// header if set by the handler. Unsafe methods are not buffered, as the preconditions
+// must be checked before making changes, use Context.CheckPreconditions instead.
+func ETag(opts ETagOptions) lars.HandlerFunc {
+
+	if opts.MaxSize == 0 {
+		opts.MaxSize = defaultETagMaxSize
+	}
+
+	if opts.Generate == nil {
+		opts.Generate = lars.NewETag
+	}
+
+	return func(c lars.Context) {
+
+		if r := c.Request(); r.Method != lars.GET && r.Method != lars.HEAD {
+			c.Next()
+			return
+		}
+
+		res := c.Response()
+		orig := res.Writer()
+		ew := &etagWriter{
+			ResponseWriter: orig,
+			maxSize:        opts.MaxSize,
+		}
+
+		res.SetWriter(ew)
+
+		defer func() {
+			res.SetWriter(orig)
+			ew.finish(c.Request(), opts)
+		}()
+
+		c.Next()
+	}
+}
+
+// etagWriter buffers the response until it's complete, or exceeds the max size
+type etagWriter struct {
+	http.ResponseWriter
+	maxSize   int
+	buff      []byte
+	status    int
+	streaming bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if w.status != http.StatusOK || (w.maxSize != -1 && len(w.buff)+len(b) > w.maxSize) {
+
+		if err := w.stream(); err != nil {
+			return 0, err
+		}
+
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buff = append(w.buff, b...)
+
+	return len(b), nil
+}
+
+// stream writes the status and any buffered data, writing
+// directly to the underlying writer from then on
+func (w *etagWriter) stream() (err error) {
+
+	w.streaming = true
+
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+
+	if len(w.buff) > 0 {
+		_, err = w.ResponseWriter.Write(w.buff)
+	}
+
+	w.buff = nil
+
+	return
+}
+
+// finish sets the ETag of the buffered response and evaluates the
+// requests preconditions before writing it, if still required.
+func (w *etagWriter) finish(r *http.Request, opts ETagOptions) {
+
+	if w.streaming || w.status == 0 {
+		return
+	}
+
+	if w.status != http.StatusOK {
+		w.stream()
+		return
+	}
+
+	h := w.Header()
+
+	etag := h.Get(lars.ETag)
+	if etag == "" {
+
+		etag = opts.Generate(w.buff)
+
+		if opts.Weak {
+			etag = "W/" + etag
+		}
+
+		h.Set(lars.ETag, etag)
+	}
+
+	var modtime time.Time
+
+	if lm := h.Get(lars.LastModified); lm != "" {
+		modtime, _ = http.ParseTime(lm)
+	}
+
+	if code := lars.EvaluatePreconditions(r, etag, modtime); code != 0 {
+
+		if code == http.StatusNotModified {
+			h.Del(lars.ContentType)
+			h.Del(lars.ContentLength)
+		}
+
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.stream()
+}
+
+func (w *etagWriter) Flush() {
+
+	if !w.streaming {
+		w.stream()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *etagWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+func TestETag(t *testing.T) {
+
+	modtime := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	l := lars.New()
+	l.Use(ETag(ETagOptions{MaxSize: 32}))
+	l.Get("/users", func(c lars.Context) {
+		c.JSON(http.StatusOK, []string{"Joey", "Patient Zero"})
+	})
+	l.Head("/users", func(c lars.Context) {
+		c.JSON(http.StatusOK, []string{"Joey", "Patient Zero"})
+	})
+	l.Get("/custom", func(c lars.Context) {
+		c.Response().Header().Set(lars.ETag, `"v1"`)
+		c.Response().Header().Set(lars.LastModified, modtime.Format(http.TimeFormat))
+		c.Text(http.StatusOK, "custom")
+	})
+	l.Get("/large", func(c lars.Context) {
+		c.Text(http.StatusOK, strings.Repeat("a", 33))
+	})
+	l.Get("/created", func(c lars.Context) {
+		c.Text(http.StatusCreated, "created")
+	})
+	l.Get("/empty", func(c lars.Context) {})
+	l.Put("/users", func(c lars.Context) {
+		c.Text(http.StatusOK, "updated")
+	})
+
+	w := serveRequest(l, lars.GET, "/users", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), `["Joey","Patient Zero"]`)
+	Equal(t, w.Header().Get(lars.ContentType), lars.ApplicationJSONCharsetUTF8)
+
+	etag := w.Header().Get(lars.ETag)
+	Equal(t, etag, lars.NewETag([]byte(`["Joey","Patient Zero"]`)))
+
+	w = serveRequest(l, lars.GET, "/users", map[string]string{lars.IfNoneMatch: `"other", ` + etag})
+	Equal(t, w.Code, http.StatusNotModified)
+	Equal(t, w.Body.Len(), 0)
+	Equal(t, w.Header().Get(lars.ContentType), "")
+	Equal(t, w.Header().Get(lars.ETag), etag)
+
+	w = serveRequest(l, lars.HEAD, "/users", map[string]string{lars.IfNoneMatch: "W/" + etag})
+	Equal(t, w.Code, http.StatusNotModified)
+
+	w = serveRequest(l, lars.GET, "/users", map[string]string{lars.IfMatch: `"other"`})
+	Equal(t, w.Code, http.StatusPreconditionFailed)
+
+	w = serveRequest(l, lars.GET, "/custom", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), `"v1"`)
+
+	w = serveRequest(l, lars.GET, "/custom", map[string]string{lars.IfModifiedSince: modtime.Format(http.TimeFormat)})
+	Equal(t, w.Code, http.StatusNotModified)
+
+	w = serveRequest(l, lars.GET, "/custom", map[string]string{lars.IfModifiedSince: modtime.Add(-time.Second).Format(http.TimeFormat)})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "custom")
+
+	w = serveRequest(l, lars.GET, "/large", map[string]string{lars.IfNoneMatch: "*"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), "")
+	Equal(t, w.Body.Len(), 33)
+
+	w = serveRequest(l, lars.GET, "/created", map[string]string{lars.IfNoneMatch: "*"})
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Header().Get(lars.ETag), "")
+	Equal(t, w.Body.String(), "created")
+
+	w = serveRequest(l, lars.GET, "/empty", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), "")
+
+	w = serveRequest(l, lars.PUT, "/users", map[string]string{lars.IfMatch: `"other"`})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ETag), "")
+
+	l2 := lars.New()
+	l2.Use(ETag(ETagOptions{Weak: true}))
+	l2.Get("/users", func(c lars.Context) {
+		c.Text(http.StatusOK, "users")
+	})
+
+	w = serveRequest(l2, lars.GET, "/users", nil)
+	Equal(t, w.Header().Get(lars.ETag), "W/"+lars.NewETag([]byte("users")))
+
+	w = serveRequest(l2, lars.GET, "/users", map[string]string{lars.IfNoneMatch: lars.NewETag([]byte("users"))})
+	Equal(t, w.Code, http.StatusNotModified)
+
+	// weak ETags never match If-Match's strong comparison
+	w = serveRequest(l2, lars.GET, "/users", map[string]string{lars.IfMatch: "W/" + lars.NewETag([]byte("users"))})
+	Equal(t, w.Code, http.StatusPreconditionFailed)
+}
+
+func TestETagFlush(t *testing.T) {
+
+	l := lars.New()
+	l.Use(ETag(ETagOptions{}))
+	l.Get("/stream", func(c lars.Context) {
+		c.Text(http.StatusOK, "first")
+		c.Response().Flush()
+		c.Response().Write([]byte(" second"))
+	})
+
+	w := serveRequest(l, lars.GET, "/stream", nil)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "first second")
+	Equal(t, w.Header().Get(lars.ETag), "")
+	Equal(t, w.Flushed, true)
+}
@@ -2,13 +2,13 @@ package middleware
 
 import (
 	"bufio"
-	"bytes"
 	"compress/flate"
 	"compress/gzip"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,10 +28,16 @@ import (
 //
 
 func TestGzip(t *testing.T) {
+
+	large := strings.Repeat("test", 300)
+
 	l := lars.New()
 	l.Use(Gzip)
 	l.Get("/test", func(c lars.Context) {
-		c.Response().Write([]byte("test"))
+		c.Response().Write([]byte(large))
+	})
+	l.Get("/tiny", func(c lars.Context) {
+		c.JSON(http.StatusNotFound, "nope")
 	})
 	l.Get("/empty", func(c lars.Context) {
 	})
@@ -49,7 +55,7 @@ func TestGzip(t *testing.T) {
 
 	b, err := ioutil.ReadAll(resp.Body)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), large)
 
 	req, _ = http.NewRequest(lars.GET, server.URL+"/test", nil)
 	req.Header.Set(lars.AcceptEncoding, "gzip")
@@ -66,7 +72,20 @@ func TestGzip(t *testing.T) {
 
 	b, err = ioutil.ReadAll(r)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), large)
+
+	// responses smaller than the min length aren't compressed
+	req, _ = http.NewRequest(lars.GET, server.URL+"/tiny", nil)
+	req.Header.Set(lars.AcceptEncoding, "gzip")
+
+	resp, err = client.Do(req)
+	Equal(t, err, nil)
+	Equal(t, resp.StatusCode, http.StatusNotFound)
+	Equal(t, resp.Header.Get(lars.ContentEncoding), "")
+
+	b, err = ioutil.ReadAll(resp.Body)
+	Equal(t, err, nil)
+	Equal(t, string(b), `"nope"`)
 
 	req, _ = http.NewRequest(http.MethodGet, server.URL+"/empty", nil)
 
@@ -80,10 +99,15 @@ func TestGzipLevel(t *testing.T) {
 	// bad gzip level
 	PanicMatches(t, func() { GzipLevel(999) }, "gzip: invalid compression level: 999")
 
+	large := strings.Repeat("test", 300)
+
 	l := lars.New()
 	l.Use(GzipLevel(flate.BestCompression))
 	l.Get("/test", func(c lars.Context) {
-		c.Response().Write([]byte("test"))
+		c.Response().Write([]byte(large))
+	})
+	l.Get("/tiny", func(c lars.Context) {
+		c.JSON(http.StatusNotFound, "nope")
 	})
 	l.Get("/empty", func(c lars.Context) {
 	})
@@ -101,7 +125,7 @@ func TestGzipLevel(t *testing.T) {
 
 	b, err := ioutil.ReadAll(resp.Body)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), large)
 
 	req, _ = http.NewRequest(lars.GET, server.URL+"/test", nil)
 	req.Header.Set(lars.AcceptEncoding, "gzip")
@@ -118,7 +142,20 @@ func TestGzipLevel(t *testing.T) {
 
 	b, err = ioutil.ReadAll(r)
 	Equal(t, err, nil)
-	Equal(t, string(b), "test")
+	Equal(t, string(b), large)
+
+	// responses smaller than the min length aren't compressed
+	req, _ = http.NewRequest(lars.GET, server.URL+"/tiny", nil)
+	req.Header.Set(lars.AcceptEncoding, "gzip")
+
+	resp, err = client.Do(req)
+	Equal(t, err, nil)
+	Equal(t, resp.StatusCode, http.StatusNotFound)
+	Equal(t, resp.Header.Get(lars.ContentEncoding), "")
+
+	b, err = ioutil.ReadAll(resp.Body)
+	Equal(t, err, nil)
+	Equal(t, string(b), `"nope"`)
 
 	req, _ = http.NewRequest(http.MethodGet, server.URL+"/empty", nil)
 
@@ -127,39 +164,89 @@ func TestGzipLevel(t *testing.T) {
 	Equal(t, resp.StatusCode, http.StatusOK)
 }
 
+func TestGzipWithOptions(t *testing.T) {
+
+	large := strings.Repeat("lars ", 100)
+
+	l := lars.New()
+	l.Use(GzipWithOptions(CompressOptions{
+		MinLength:    100,
+		ContentTypes: []string{"text/*", lars.ApplicationJSON},
+		Encodings:    []string{Deflate},
+	}))
+	l.Get("/text", func(c lars.Context) {
+		c.Text(http.StatusOK, large)
+	})
+	l.Get("/json-error", func(c lars.Context) {
+		c.JSON(http.StatusBadRequest, "bad")
+	})
+	l.Get("/xml", func(c lars.Context) {
+		c.XMLBytes(http.StatusOK, []byte(large))
+	})
+
+	w := serveRequest(l, lars.GET, "/text", map[string]string{lars.AcceptEncoding: "gzip, deflate"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), lars.Gzip)
+
+	r, err := gzip.NewReader(w.Body)
+	Equal(t, err, nil)
+	b, err := ioutil.ReadAll(r)
+	Equal(t, err, nil)
+	Equal(t, string(b), large)
+
+	w = serveRequest(l, lars.GET, "/json-error", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Code, http.StatusBadRequest)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Header().Get(lars.ContentType), lars.ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), `"bad"`)
+
+	w = serveRequest(l, lars.GET, "/xml", map[string]string{lars.AcceptEncoding: "gzip"})
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(lars.ContentEncoding), "")
+	Equal(t, w.Header().Get(lars.ContentType), lars.ApplicationXMLCharsetUTF8)
+}
+
 func TestGzipFlush(t *testing.T) {
 
 	rec := httptest.NewRecorder()
-	buff := new(bytes.Buffer)
+	gw := &compressWriter{ResponseWriter: rec, compressor: defaultGzip, coding: lars.Gzip}
 
-	w := gzip.NewWriter(buff)
-	gw := gzipWriter{Writer: w, ResponseWriter: rec}
+	Equal(t, rec.Body.Len(), 0)
 
-	Equal(t, buff.Len(), 0)
-
-	err := gw.Flush()
+	_, err := gw.Write([]byte("x"))
 	Equal(t, err, nil)
 
-	n1 := buff.Len()
-	NotEqual(t, n1, 0)
+	n0 := rec.Body.Len()
+
+	gw.Flush()
 
-	_, err = gw.Write([]byte("x"))
+	n1 := rec.Body.Len()
+	NotEqual(t, n1, n0)
+	Equal(t, rec.Flushed, true)
+	Equal(t, rec.Header().Get(lars.ContentEncoding), lars.Gzip)
+
+	_, err = gw.Write([]byte("y"))
 	Equal(t, err, nil)
 
-	n2 := buff.Len()
+	n2 := rec.Body.Len()
 	Equal(t, n1, n2)
 
-	err = gw.Flush()
+	gw.Flush()
+	NotEqual(t, n2, rec.Body.Len())
+
+	gw.close()
+
+	r, err := gzip.NewReader(rec.Body)
+	Equal(t, err, nil)
+	b, err := ioutil.ReadAll(r)
 	Equal(t, err, nil)
-	NotEqual(t, n2, buff.Len())
+	Equal(t, string(b), "xy")
 }
 
 func TestGzipCloseNotify(t *testing.T) {
 
 	rec := newCloseNotifyingRecorder()
-	buf := new(bytes.Buffer)
-	w := gzip.NewWriter(buf)
-	gw := gzipWriter{Writer: w, ResponseWriter: rec}
+	gw := &compressWriter{ResponseWriter: rec, compressor: defaultGzip, coding: lars.Gzip}
 	closed := false
 	notifier := gw.CloseNotify()
 	rec.close()
@@ -176,9 +263,7 @@ func TestGzipCloseNotify(t *testing.T) {
 func TestGzipHijack(t *testing.T) {
 
 	rec := newCloseNotifyingRecorder()
-	buf := new(bytes.Buffer)
-	w := gzip.NewWriter(buf)
-	gw := gzipWriter{Writer: w, ResponseWriter: rec}
+	gw := &compressWriter{ResponseWriter: rec, compressor: defaultGzip, coding: lars.Gzip}
 
 	_, bufrw, err := gw.Hijack()
 	Equal(t, err, nil)
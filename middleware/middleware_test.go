@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-playground/lars"
+)
+
+func serveRequest(l *lars.LARS, method string, path string, headers map[string]string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest(method, path, nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+	return w
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime"
+
+	"github.com/go-playground/lars"
+)
+
+const defaultStackSize = 4 << 10 // 4KB
+
+// RecoveryFunc is called with the recovered panic value and
+// the stack trace, if captured, at the time of the panic.
+type RecoveryFunc func(c lars.Context, err interface{}, stack []byte)
+
+// RecoveryOptions contains the options used by the Recovery middleware;
+// the zero value is a usable configuration.
+type RecoveryOptions struct {
+
+	// StackSize is the maximum size of the stack trace captured, default 4KB.
+	StackSize int
+
+	// StackAll captures the stack traces of all goroutines rather
+	// than only the one that panicked.
+	StackAll bool
+
+	// DisableStack disables capturing the stack trace, stack will be nil.
+	DisableStack bool
+
+	// Logger logs the panic, by default using the standard log package;
+	// set to a no-op function to disable logging.
+	Logger RecoveryFunc
+
+	// Handler renders the panic response, by default a 500 Internal Server Error.
+	// It is not called when the response has already been committed.
+	Handler RecoveryFunc
+
+	// Report is an optional callback for error reporting services.
+	Report RecoveryFunc
+}
+
+// Recovery returns a middleware which recovers from panics further along the
+// chain, logs them, optionally reports them and renders the panic response.
+// http.ErrAbortHandler is re-panicked so the server can abort the response.
+func Recovery(opts RecoveryOptions) lars.HandlerFunc {
+
+	if opts.StackSize <= 0 {
+		opts.StackSize = defaultStackSize
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = defaultRecoveryLogger
+	}
+
+	if opts.Handler == nil {
+		opts.Handler = defaultRecoveryHandler
+	}
+
+	return func(c lars.Context) {
+
+		defer func() {
+
+			err := recover()
+			if err == nil {
+				return
+			}
+
+			if isAbortHandler(err) {
+				panic(err)
+			}
+
+			var stack []byte
+
+			if !opts.DisableStack {
+				stack = make([]byte, opts.StackSize)
+				stack = stack[:runtime.Stack(stack, opts.StackAll)]
+			}
+
+			opts.Logger(c, err, stack)
+
+			if opts.Report != nil {
+				opts.Report(c, err, stack)
+			}
+
+			if !c.Response().Committed() {
+				opts.Handler(c, err, stack)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+func defaultRecoveryLogger(c lars.Context, err interface{}, stack []byte) {
+	log.Printf("recovering from panic: %+v\nStack Trace:\n%s", err, stack)
+}
+
+func defaultRecoveryHandler(c lars.Context, err interface{}, stack []byte) {
+	http.Error(c.Response(), http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
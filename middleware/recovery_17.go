@@ -0,0 +1,7 @@
+// +build !go1.8
+
+package middleware
+
+func isAbortHandler(err interface{}) bool {
+	return false
+}
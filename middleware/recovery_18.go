@@ -0,0 +1,9 @@
+// +build go1.8
+
+package middleware
+
+import "net/http"
+
+func isAbortHandler(err interface{}) bool {
+	return err == http.ErrAbortHandler
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/lars"
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+func TestRecovery(t *testing.T) {
+
+	buff := new(bytes.Buffer)
+	log.SetOutput(buff)
+	defer log.SetOutput(os.Stderr)
+
+	l := lars.New()
+	l.Use(Recovery(RecoveryOptions{}))
+	l.Get("/panic", func(c lars.Context) {
+		panic("boom")
+	})
+	l.Get("/committed", func(c lars.Context) {
+		c.Text(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	r, _ := http.NewRequest(lars.GET, "/panic", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusInternalServerError)
+	Equal(t, w.Body.String(), "Internal Server Error\n")
+	Equal(t, strings.Contains(buff.String(), "recovering from panic: boom"), true)
+	Equal(t, strings.Contains(buff.String(), "Stack Trace:\ngoroutine"), true)
+
+	r, _ = http.NewRequest(lars.GET, "/committed", nil)
+	w = httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "partial")
+}
+
+func TestRecoveryOptions(t *testing.T) {
+
+	var logged, reported interface{}
+	var stack []byte
+
+	l := lars.New()
+	l.Use(Recovery(RecoveryOptions{
+		DisableStack: true,
+		Logger: func(c lars.Context, err interface{}, s []byte) {
+			logged = err
+			stack = s
+		},
+		Report: func(c lars.Context, err interface{}, s []byte) {
+			reported = err
+		},
+		Handler: func(c lars.Context, err interface{}, s []byte) {
+			c.JSON(http.StatusServiceUnavailable, err)
+		},
+	}))
+	l.Get("/panic", func(c lars.Context) {
+		panic("boom")
+	})
+	l.Get("/abort", func(c lars.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	r, _ := http.NewRequest(lars.GET, "/panic", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusServiceUnavailable)
+	Equal(t, w.Body.String(), `"boom"`)
+	Equal(t, logged, "boom")
+	Equal(t, reported, "boom")
+	Equal(t, len(stack), 0)
+
+	logged = nil
+
+	r, _ = http.NewRequest(lars.GET, "/abort", nil)
+	w = httptest.NewRecorder()
+	PanicMatches(t, func() { l.Serve().ServeHTTP(w, r) }, http.ErrAbortHandler.Error())
+	Equal(t, logged, nil)
+}
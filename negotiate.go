@@ -0,0 +1,236 @@
+package lars
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RendererFunc renders data, with the status code, as the media type it was registered for.
+type RendererFunc func(c Context, code int, data interface{}) error
+
+// NotAcceptableFunc is called by Negotiate when none of the offered
+// media types are acceptable to the client.
+type NotAcceptableFunc func(c Context, offers []string) error
+
+var (
+	defaultNotAcceptableHandler = func(c Context, offers []string) error {
+		http.Error(c.Response(), http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return nil
+	}
+
+	jsonRenderer = func(c Context, code int, data interface{}) error {
+		return c.JSON(code, data)
+	}
+
+	xmlRenderer = func(c Context, code int, data interface{}) error {
+		return c.XML(code, data)
+	}
+
+	textRenderer = func(c Context, code int, data interface{}) error {
+
+		switch d := data.(type) {
+		case string:
+			return c.Text(code, d)
+		case []byte:
+			return c.TextBytes(code, d)
+		default:
+			return c.Text(code, fmt.Sprint(d))
+		}
+	}
+)
+
+// AcceptValue is a single value, and it's q-value, from an Accept style header
+// i.e. Accept, Accept-Encoding or Accept-Language.
+type AcceptValue struct {
+	Value string // lowercased with any params removed
+	Q     float64
+}
+
+// acceptRange is a single media range from an Accept header
+type acceptRange struct {
+	typ    string
+	subtyp string
+	q      float64
+}
+
+// RegisterRenderer registers, or replaces, the renderer used by Negotiate for the media
+// type i.e. ApplicationMsgpack or ApplicationProtobuf. JSON, XML and plain text
+// renderers are registered by default, renderers are offered in the order registered.
+func (l *LARS) RegisterRenderer(mediaType string, fn RendererFunc) {
+
+	mediaType = strings.ToLower(mediaType)
+
+	if _, ok := l.renderers[mediaType]; !ok {
+		l.rendererOrder = append(l.rendererOrder, mediaType)
+	}
+
+	l.renderers[mediaType] = fn
+}
+
+// RegisterNotAcceptable registers the function called by Negotiate when none of the
+// offered media types are acceptable, by default a 406 Not Acceptable is written.
+func (l *LARS) RegisterNotAcceptable(fn NotAcceptableFunc) {
+	l.notAcceptable = fn
+}
+
+// Negotiate renders data, with the status code, using the renderer of the offered media
+// type most preferred by the requests Accept header, taking q-values and wildcards
+// into account; ties are won by the first offered. When no offers are provided all
+// registered renderers are offered and when no Accept header is present the first
+// offer is used. If nothing is acceptable the NotAcceptableFunc is called.
+func (c *Ctx) Negotiate(code int, data interface{}, offers ...string) error {
+
+	if len(offers) == 0 {
+		offers = c.lars.rendererOrder
+	}
+
+	mediaType := negotiateMediaType(c.request.Header.Get(Accept), offers)
+	if mediaType == blank {
+		return c.lars.notAcceptable(c.parent, offers)
+	}
+
+	fn, ok := c.lars.renderers[strings.ToLower(mediaType)]
+	if !ok {
+		return fmt.Errorf("lars: no renderer registered for media type '%s'", mediaType)
+	}
+
+	return fn(c.parent, code, data)
+}
+
+// negotiateMediaType returns the offer with the highest q-value in the Accept header,
+// the first offer when the header is blank or blank when none are acceptable.
+func negotiateMediaType(accept string, offers []string) string {
+
+	if len(offers) == 0 {
+		return blank
+	}
+
+	if accept == blank {
+		return offers[0]
+	}
+
+	ranges := parseMediaRanges(accept)
+
+	var best string
+	var bestQ float64
+
+	for _, offer := range offers {
+
+		if q := acceptQuality(ranges, strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the q-value of the most specific media range matching the media type
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+
+	typ, subtyp := mediaType, blank
+
+	if idx := strings.IndexByte(mediaType, '/'); idx != -1 {
+		typ, subtyp = mediaType[:idx], mediaType[idx+1:]
+	}
+
+	specificity := -1
+	q := 0.0
+
+	for _, r := range ranges {
+
+		var s int
+
+		switch {
+		case r.typ == typ && r.subtyp == subtyp:
+			s = 2
+		case r.typ == typ && r.subtyp == "*":
+			s = 1
+		case r.typ == "*" && r.subtyp == "*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			specificity, q = s, r.q
+		}
+	}
+
+	return q
+}
+
+// ParseAccept parses the comma separated values of an Accept style header i.e.
+// "gzip;q=0.5, deflate", skipping blank values; values without a q-value have
+// a q-value of 1 and those with an invalid one 0.
+func ParseAccept(header string) []AcceptValue {
+
+	parts := strings.Split(header, ",")
+	values := make([]AcceptValue, 0, len(parts))
+
+	for _, part := range parts {
+
+		v := AcceptValue{Q: 1}
+
+		if idx := strings.IndexByte(part, ';'); idx != -1 {
+			v.Q = parseQValue(part[idx+1:])
+			part = part[:idx]
+		}
+
+		if v.Value = strings.ToLower(strings.TrimSpace(part)); v.Value == blank {
+			continue
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// parseMediaRanges parses the media ranges of an Accept header
+func parseMediaRanges(accept string) []acceptRange {
+
+	values := ParseAccept(accept)
+	ranges := make([]acceptRange, 0, len(values))
+
+	for _, v := range values {
+
+		r := acceptRange{q: v.Q}
+
+		if idx := strings.IndexByte(v.Value, '/'); idx != -1 {
+			r.typ, r.subtyp = v.Value[:idx], v.Value[idx+1:]
+		} else if v.Value == "*" {
+			r.typ, r.subtyp = "*", "*"
+		} else {
+			continue
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// parseQValue parses the q-value from the params following a value i.e. "q=0.5",
+// returning 1 when not present and 0 when invalid.
+func parseQValue(params string) float64 {
+
+	for _, param := range strings.Split(params, ";") {
+
+		param = strings.TrimSpace(param)
+
+		if len(param) < 2 || (param[0] != 'q' && param[0] != 'Q') || param[1] != '=' {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(param[2:], 64)
+		if err != nil || q < 0 || q > 1 {
+			return 0
+		}
+
+		return q
+	}
+
+	return 1
+}
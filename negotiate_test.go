@@ -0,0 +1,123 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestNegotiate(t *testing.T) {
+
+	type zombie struct {
+		ID   int    `json:"id" xml:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+
+	acceptRequest := func(path string, accept string, l *LARS) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(GET, path, nil)
+		if accept != blank {
+			r.Header.Set(Accept, accept)
+		}
+		w := httptest.NewRecorder()
+		l.Serve().ServeHTTP(w, r)
+		return w
+	}
+
+	l := New()
+	l.RegisterRenderer(ApplicationMsgpack, func(c Context, code int, data interface{}) error {
+		return c.Text(code, "msgpack")
+	})
+	l.Get("/all", func(c Context) error {
+		return c.Negotiate(http.StatusOK, zombie{1, "Patient Zero"})
+	})
+	l.Get("/offers", func(c Context) error {
+		return c.Negotiate(http.StatusCreated, "created", TextPlain, ApplicationJSON)
+	})
+	l.Get("/unregistered", func(c Context) error {
+		return c.Negotiate(http.StatusOK, "data", "application/yaml")
+	})
+
+	w := acceptRequest("/all", blank, l)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), "{\"id\":1,\"name\":\"Patient Zero\"}")
+
+	w = acceptRequest("/all", "application/xml", l)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), ApplicationXMLCharsetUTF8)
+
+	w = acceptRequest("/all", "application/json;q=0.5, application/xml;q=0.9", l)
+	Equal(t, w.Header().Get(ContentType), ApplicationXMLCharsetUTF8)
+
+	w = acceptRequest("/all", "text/*, application/json;q=0.1", l)
+	Equal(t, w.Header().Get(ContentType), TextPlainCharsetUTF8)
+	Equal(t, w.Body.String(), "{1 Patient Zero}")
+
+	w = acceptRequest("/all", "application/*;q=0.5, application/xml;q=0", l)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+
+	w = acceptRequest("/all", "application/msgpack", l)
+	Equal(t, w.Body.String(), "msgpack")
+
+	w = acceptRequest("/all", "*/*", l)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+
+	w = acceptRequest("/all", "image/png", l)
+	Equal(t, w.Code, http.StatusNotAcceptable)
+
+	w = acceptRequest("/offers", "*/*", l)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "created")
+
+	w = acceptRequest("/offers", "application/xml", l)
+	Equal(t, w.Code, http.StatusNotAcceptable)
+
+	w = acceptRequest("/offers", "text/plain;q=0.2, application/json;q=0.8", l)
+	Equal(t, w.Code, http.StatusCreated)
+	Equal(t, w.Body.String(), "\"created\"")
+
+	w = acceptRequest("/unregistered", blank, l)
+	Equal(t, w.Code, http.StatusInternalServerError)
+
+	l.RegisterNotAcceptable(func(c Context, offers []string) error {
+		return NewHTTPError(http.StatusNotAcceptable, "not_acceptable", "supported: "+offers[0])
+	})
+
+	w = acceptRequest("/offers", "image/png", l)
+	Equal(t, w.Code, http.StatusNotAcceptable)
+	Equal(t, w.Header().Get(ContentType), ApplicationJSONCharsetUTF8)
+	Equal(t, w.Body.String(), "{\"status\":406,\"code\":\"not_acceptable\",\"message\":\"supported: text/plain\"}")
+}
+
+func TestParseQValue(t *testing.T) {
+
+	Equal(t, parseQValue(""), 1.0)
+	Equal(t, parseQValue(" q=0.5"), 0.5)
+	Equal(t, parseQValue("level=1; Q=0.3"), 0.3)
+	Equal(t, parseQValue("q=abc"), 0.0)
+	Equal(t, parseQValue("q=2"), 0.0)
+}
+
+func TestParseAccept(t *testing.T) {
+
+	Equal(t, ParseAccept("GZIP;q=0.5, deflate,, *;q=0"), []AcceptValue{
+		{Value: "gzip", Q: 0.5},
+		{Value: "deflate", Q: 1},
+		{Value: "*", Q: 0},
+	})
+	Equal(t, ParseAccept("text/html;level=1;q=abc"), []AcceptValue{{Value: "text/html", Q: 0}})
+	Equal(t, len(ParseAccept("")), 0)
+}
@@ -6,7 +6,10 @@
 
 package lars
 
-import "net/url"
+import (
+	"net/url"
+	"strings"
+)
 
 type nodeType uint8
 
@@ -19,6 +22,13 @@ const (
 type methodChain struct {
 	handlerName string
 	chain       HandlersChain
+
+	// path is the full path as registered, including any param constraints
+	path string
+
+	// constraints contains a param constraint, or nil, for each of the routes
+	// params in order; nil when the route has no constraints.
+	constraints []*paramConstraint
 }
 
 type existingParams map[string]struct{}
@@ -69,9 +79,64 @@ func (n *node) incrementChildPrio(pos int) int {
 	return newPos
 }
 
+// insertion tracks a route being added when collecting registration errors;
+// each node is copied before being modified, leaving the original tree
+// unchanged on panic, and the existing route the path conflicts with is
+// recorded before panicking.
+type insertion struct {
+	conflict *methodChain
+}
+
+// copy returns a copy of the node, including its children slice,
+// which can be modified without affecting the original.
+func (n *node) copy() *node {
+
+	c := *n
+	c.children = make([]*node, len(n.children))
+	copy(c.children, n.children)
+
+	return &c
+}
+
+// child returns the child at i, replacing it with a copy first when inserting
+func (n *node) child(i int, in *insertion) *node {
+
+	if in != nil {
+		n.children[i] = n.children[i].copy()
+	}
+
+	return n.children[i]
+}
+
+// conflicts records the first route of the node, or its descendants, as the
+// route being inserted conflicts with when inserting.
+func (n *node) conflicts(in *insertion) {
+
+	if in != nil && in.conflict == nil {
+		in.conflict = n.firstRoute()
+	}
+}
+
+// firstRoute returns the handler of the node, or its first descendant with one
+func (n *node) firstRoute() *methodChain {
+
+	if n.handler != nil {
+		return n.handler
+	}
+
+	for _, c := range n.children {
+		if mc := c.firstRoute(); mc != nil {
+			return mc
+		}
+	}
+
+	return nil
+}
+
 // addRoute adds a node with the given handle to the path.
 // here we set a Middleware because we have  to transfer all route's middlewares (it's a chain of functions) (with it's handler) to the node
-func (n *node) add(path string, handlerName string, handler HandlersChain) (lp uint8) {
+// in, when non-nil, must be used with a copy of the root node; see insertion.
+func (n *node) add(path string, handlerName string, handler HandlersChain, in *insertion) (lp uint8) {
 
 	var err error
 
@@ -80,6 +145,13 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 	}
 
 	existing := make(existingParams)
+	mc := &methodChain{
+		handlerName: handlerName,
+		chain:       handler,
+		path:        path,
+	}
+
+	path, mc.constraints = parseConstraints(path)
 	fullPath := path
 
 	if path, err = url.QueryUnescape(path); err != nil {
@@ -129,7 +201,7 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 				path = path[i:]
 
 				if n.wildChild {
-					n = n.children[0]
+					n = n.child(0, in)
 					n.priority++
 					numParams--
 
@@ -144,6 +216,7 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 						}
 					}
 
+					n.conflicts(in)
 					panic("path segment '" + path +
 						"' conflicts with existing wildcard '" + n.path +
 						"' in path '" + fullPath + "'")
@@ -153,7 +226,7 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 
 				// slash after param
 				if n.nType == hasParams && c == slashByte && len(n.children) == 1 {
-					n = n.children[0]
+					n = n.child(0, in)
 					n.priority++
 					continue walk
 				}
@@ -161,6 +234,7 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 				// Check if a child with the next path byte exists
 				for i := 0; i < len(n.indices); i++ {
 					if c == n.indices[i] {
+						n.child(i, in)
 						i = n.incrementChildPrio(i)
 						n = n.children[i]
 						continue walk
@@ -177,29 +251,27 @@ func (n *node) add(path string, handlerName string, handler HandlersChain) (lp u
 					n.incrementChildPrio(len(n.indices) - 1)
 					n = child
 				}
-				n.insertChild(numParams, existing, path, fullPath, handlerName, handler)
+				n.insertChild(numParams, existing, path, fullPath, mc, in)
 				return
 
 			} else if i == len(path) { // Make node a (in-path) leaf
 				if n.handler != nil {
+					n.conflicts(in)
 					panic("handlers are already registered for path '" + fullPath + "'")
 				}
-				n.handler = &methodChain{
-					handlerName: handlerName,
-					chain:       handler,
-				}
+				n.handler = mc
 			}
 			return
 		}
 	} else { // Empty tree
-		n.insertChild(numParams, existing, path, fullPath, handlerName, handler)
+		n.insertChild(numParams, existing, path, fullPath, mc, in)
 		n.nType = isRoot
 	}
 
 	return
 }
 
-func (n *node) insertChild(numParams uint8, existing existingParams, path string, fullPath string, handlerName string, handler HandlersChain) {
+func (n *node) insertChild(numParams uint8, existing existingParams, path string, fullPath string, mc *methodChain, in *insertion) {
 
 	var offset int // already handled bytes of the path
 
@@ -227,6 +299,11 @@ func (n *node) insertChild(numParams uint8, existing existingParams, path string
 		// check if this Node existing children which would be
 		// unreachable if we insert the wildcard here
 		if len(n.children) > 0 {
+
+			for _, child := range n.children {
+				child.conflicts(in)
+			}
+
 			panic("wildcard route '" + path[i:end] +
 				"' conflicts with existing children in path '" + fullPath + "'")
 		}
@@ -275,6 +352,7 @@ func (n *node) insertChild(numParams uint8, existing existingParams, path string
 			}
 
 			if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
+				n.conflicts(in)
 				panic("catch-all conflicts with existing handle for the path segment root in path '" + fullPath + "'")
 			}
 
@@ -300,7 +378,7 @@ func (n *node) insertChild(numParams uint8, existing existingParams, path string
 			child = &node{
 				path:     path[i:],
 				nType:    matchesAny,
-				handler:  &methodChain{handlerName: handlerName, chain: handler},
+				handler:  mc,
 				priority: 1,
 			}
 			n.children = []*node{child}
@@ -315,13 +393,32 @@ func (n *node) insertChild(numParams uint8, existing existingParams, path string
 
 	// insert remaining path part and handle to the leaf
 	n.path = path[offset:]
-	n.handler = &methodChain{handlerName: handlerName, chain: handler}
+	n.handler = mc
+}
+
+// walk recursively visits every node that has a handler registered, passing
+// the full path built from the node paths along the way and the nodes depth
+// within the tree, the root being 0.
+func (n *node) walk(path string, depth int, fn func(path string, depth int, mc *methodChain)) {
+
+	path += n.path
+
+	if n.handler != nil {
+		fn(path, depth, n.handler)
+	}
+
+	for _, child := range n.children {
+		child.walk(path, depth+1, fn)
+	}
 }
 
 // Returns the handle registered with the given path (key).
-func (n *node) find(path string, po Params) (handler HandlersChain, p Params, handlerName string) {
+// unescape must be set when the path is escaped and the param values unescaped
+// once found, so the param constraints are checked against the unescaped values.
+func (n *node) find(path string, po Params, unescape bool) (handler HandlersChain, p Params, handlerName string, routePath string) {
 
 	p = po
+	start := len(p)
 
 walk: // Outer loop for walking the tree
 	for {
@@ -374,8 +471,11 @@ walk: // Outer loop for walking the tree
 					}
 
 					if n.handler != nil {
-						handler = n.handler.chain
-						handlerName = n.handler.handlerName
+						if n.handler.validParams(p[start:], unescape) {
+							handler = n.handler.chain
+							handlerName = n.handler.handlerName
+							routePath = n.handler.path
+						}
 						return
 					} else if len(n.children) == 1 {
 						// No handle found. Check if a handle for this path
@@ -392,8 +492,11 @@ walk: // Outer loop for walking the tree
 					p[i].Key = WildcardParam
 					p[i].Value = path[1:]
 
-					handler = n.handler.chain
-					handlerName = n.handler.handlerName
+					if n.handler.validParams(p[start:], unescape) {
+						handler = n.handler.chain
+						handlerName = n.handler.handlerName
+						routePath = n.handler.path
+					}
 					return
 
 					// can't happen, but left here in case I'm wrong
@@ -406,8 +509,8 @@ walk: // Outer loop for walking the tree
 
 			// We should have reached the node containing the handle.
 			// Check if this node has a handle registered.
-			if n.handler != nil {
-				if handler, handlerName = n.handler.chain, n.handler.handlerName; handler != nil {
+			if n.handler != nil && n.handler.validParams(p[start:], unescape) {
+				if handler, handlerName, routePath = n.handler.chain, n.handler.handlerName, n.handler.path; handler != nil {
 					return
 				}
 			}
@@ -417,3 +520,166 @@ walk: // Outer loop for walking the tree
 		return
 	}
 }
+
+// findCaseInsensitive makes a case-insensitive lookup of the given path and tries to find a
+// handler. It can optionally also fix trailing slashes. It returns the case-corrected path
+// and a bool indicating whether the lookup was successful; the routes param
+// constraints must be satisfied, just as with find, including unescape.
+func (n *node) findCaseInsensitive(path string, fixTrailingSlash bool, unescape bool) (ciPath []byte, found bool) {
+	return n.findCaseInsensitiveParams(path, nil, nil, fixTrailingSlash, unescape)
+}
+
+// findCaseInsensitiveParams is findCaseInsensitive with p containing the param values
+// captured so far, for checking param constraints, and parent the handler of the
+// previous node, if any, used when removing a trailing slash.
+func (n *node) findCaseInsensitiveParams(path string, p Params, parent *methodChain, fixTrailingSlash bool, unescape bool) (ciPath []byte, found bool) {
+
+	ciPath = make([]byte, 0, len(path)+1) // preallocate enough memory
+
+	// Outer loop for walking the tree
+	for len(path) >= len(n.path) && strings.EqualFold(path[:len(n.path)], n.path) {
+
+		path = path[len(n.path):]
+		ciPath = append(ciPath, n.path...)
+
+		if len(path) > 0 {
+
+			// If this node does not have a wildcard (param or catchAll) child,
+			// we can just look up the next child node and continue to walk down
+			// the tree
+			if !n.wildChild {
+
+				c := lowerASCII(path[0])
+
+				for i := 0; i < len(n.indices); i++ {
+
+					// must use recursive approach since both index and
+					// lowercase index could exist. We must check both.
+					if c == lowerASCII(n.indices[i]) {
+						if out, found := n.children[i].findCaseInsensitiveParams(path, p, n.handler, fixTrailingSlash, unescape); found {
+							return append(ciPath, out...), true
+						}
+					}
+				}
+
+				// Nothing found. We can recommend to redirect to the same URL
+				// without a trailing slash if a leaf exists for that path
+				found = fixTrailingSlash && path == basePath && n.handler != nil && n.handler.validParams(p, unescape)
+				return
+			}
+
+			parent = n.handler
+			n = n.children[0]
+
+			switch n.nType {
+			case hasParams:
+
+				// find param end (either '/' or path end)
+				k := 0
+				for k < len(path) && path[k] != slashByte {
+					k++
+				}
+
+				// add param value, as is, to case insensitive path
+				ciPath = append(ciPath, path[:k]...)
+				p = append(p, Param{Key: n.path[1:], Value: path[:k]})
+
+				// we need to go deeper!
+				if k < len(path) {
+
+					if len(n.children) > 0 {
+						path = path[k:]
+						parent = n.handler
+						n = n.children[0]
+						continue
+					}
+
+					// ... but we can't
+					if fixTrailingSlash && len(path) == k+1 && n.handler != nil && n.handler.validParams(p, unescape) {
+						return ciPath, true
+					}
+
+					return
+				}
+
+				if n.handler != nil {
+					return ciPath, n.handler.validParams(p, unescape)
+				} else if fixTrailingSlash && len(n.children) == 1 {
+
+					// No handle found. Check if a handle for this path + a
+					// trailing slash exists
+					n = n.children[0]
+
+					if n.path == basePath && n.handler != nil && n.handler.validParams(p, unescape) {
+						return append(ciPath, slashByte), true
+					}
+				}
+
+				return
+
+			case matchesAny:
+
+				if !n.handler.validParams(append(p, Param{Key: WildcardParam, Value: path[1:]}), unescape) {
+					return
+				}
+
+				return append(ciPath, path...), true
+			}
+
+			return
+		}
+
+		// We should have reached the node containing the handle.
+		// Check if this node has a handle registered.
+		if n.handler != nil {
+			return ciPath, n.handler.validParams(p, unescape)
+		}
+
+		// No handle found.
+		// Try to fix the path by adding a trailing slash
+		if fixTrailingSlash {
+			for i := 0; i < len(n.indices); i++ {
+				if n.indices[i] == slashByte {
+
+					n = n.children[i]
+
+					if (len(n.path) == 1 && n.handler != nil && n.handler.validParams(p, unescape)) ||
+						(n.nType == matchesAny && n.children[0].handler != nil &&
+							n.children[0].handler.validParams(append(p, Param{Key: WildcardParam}), unescape)) {
+						return append(ciPath, slashByte), true
+					}
+
+					return
+				}
+			}
+		}
+
+		return
+	}
+
+	// Nothing found.
+	// Try to fix the path by adding / removing a trailing slash
+	if fixTrailingSlash {
+
+		if path == basePath {
+			return ciPath, parent != nil && parent.validParams(p, unescape)
+		}
+
+		if len(path)+1 == len(n.path) && n.path[len(path)] == slashByte &&
+			strings.EqualFold(path, n.path[:len(path)]) && n.handler != nil && n.handler.validParams(p, unescape) {
+			return append(ciPath, n.path...), true
+		}
+	}
+
+	return
+}
+
+// lowerASCII returns the lowercase of an ASCII letter
+func lowerASCII(c byte) byte {
+
+	if c >= 'A' && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+
+	return c
+}
@@ -83,3 +83,104 @@ func TestBadRoutes(t *testing.T) {
 	PanicMatches(t, func() { l.Get("/refewrfewf/fefef") }, "No handler mapped to path:/refewrfewf/fefef")
 	PanicMatches(t, func() { l.Get("/users//:id", basicHandler) }, "Bad path '/users//:id' contains duplicate // at index:6")
 }
+
+func TestParamConstraints(t *testing.T) {
+
+	fn := func(c Context) {
+		if _, err := c.Response().Write([]byte(c.Param("id") + c.Param("name") + c.Param("mid"))); err != nil {
+			panic(err)
+		}
+	}
+
+	l := New()
+	l.SetHandle405MethodNotAllowed(true)
+	l.Get("/users/:id<int>", fn)
+	l.Get("/users/:id/profile", fn)
+	l.Delete("/users/:id<uint>", fn)
+	l.Get("/files/:name<[a-z0-9_-]+>", fn)
+	l.Get("/regex/:name<(?P<first>[a-z]{2})[0-9]+>/:mid<alpha>", fn)
+	l.Get("/all/:id<uuid>/*", fn)
+
+	code, body := request(GET, "/users/13", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "13")
+
+	code, _ = request(GET, "/users/joeybloggs", l)
+	Equal(t, code, http.StatusNotFound)
+
+	// constraints are route specific
+	code, body = request(GET, "/users/joeybloggs/profile", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "joeybloggs")
+
+	code, _ = request(DELETE, "/users/-13", l)
+	Equal(t, code, http.StatusMethodNotAllowed)
+
+	code, body = request(GET, "/files/my_file-1", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "my_file-1")
+
+	code, _ = request(GET, "/files/My.File", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/regex/ab12/cd", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "ab12cd")
+
+	code, _ = request(GET, "/regex/abc12/cd", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, _ = request(GET, "/regex/ab12/c1", l)
+	Equal(t, code, http.StatusNotFound)
+
+	code, body = request(GET, "/all/0f8fad5b-d9cb-469f-a165-70867728950e/test", l)
+	Equal(t, code, http.StatusOK)
+	Equal(t, body, "0f8fad5b-d9cb-469f-a165-70867728950e")
+
+	code, _ = request(GET, "/all/13/test", l)
+	Equal(t, code, http.StatusNotFound)
+
+	routes := l.Routes()
+	Equal(t, routes[0].Path, "/all/:id<uuid>/*")
+	Equal(t, routes[1].Path, "/files/:name<[a-z0-9_-]+>")
+
+	PanicMatches(t, func() { l.Get("/bad/:id<<int>", fn) }, "unterminated param constraint in path '/bad/:id<<int>'")
+	PanicMatches(t, func() { l.Get("/bad/:id<>", fn) }, "empty param constraint in path '/bad/:id<>'")
+	PanicMatches(t, func() { l.Get("/bad/:id<[a-z>", fn) }, "invalid param constraint '[a-z' in path '/bad/:id<[a-z>': error parsing regexp: missing closing ]: `[a-z)$`")
+}
+
+func TestTypedParams(t *testing.T) {
+
+	l := New()
+	l.Get("/:int/:uint/:float/:bool", func(c Context) {
+
+		i, err := c.ParamInt("int")
+		Equal(t, err, nil)
+		Equal(t, i, -13)
+
+		i64, err := c.ParamInt64("int")
+		Equal(t, err, nil)
+		Equal(t, i64, int64(-13))
+
+		u64, err := c.ParamUint64("uint")
+		Equal(t, err, nil)
+		Equal(t, u64, uint64(13))
+
+		f64, err := c.ParamFloat64("float")
+		Equal(t, err, nil)
+		Equal(t, f64, 13.5)
+
+		b, err := c.ParamBool("bool")
+		Equal(t, err, nil)
+		Equal(t, b, true)
+
+		_, err = c.ParamInt("bool")
+		NotEqual(t, err, nil)
+
+		_, err = c.ParamUint64("int")
+		NotEqual(t, err, nil)
+	})
+
+	code, _ := request(GET, "/-13/13/13.5/true", l)
+	Equal(t, code, http.StatusOK)
+}
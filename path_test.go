@@ -0,0 +1,87 @@
+// Copyright 2016 Dean Karn.
+// Copyright 2013 Julien Schmidt.
+// Based on the path package, Copyright 2009 The Go Authors.
+// All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file at https://raw.githubusercontent.com/julienschmidt/httprouter/master/LICENSE.
+
+package lars
+
+import (
+	"testing"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestCleanPath(t *testing.T) {
+
+	tests := []struct {
+		path, result string
+	}{
+		// Already clean
+		{"/", "/"},
+		{"/abc", "/abc"},
+		{"/a/b/c", "/a/b/c"},
+		{"/abc/", "/abc/"},
+		{"/a/b/c/", "/a/b/c/"},
+
+		// missing root
+		{"", "/"},
+		{"a/", "/a/"},
+		{"abc", "/abc"},
+		{"abc/def", "/abc/def"},
+		{"a/b/c", "/a/b/c"},
+
+		// Remove doubled slash
+		{"//", "/"},
+		{"/abc//", "/abc/"},
+		{"/abc/def//", "/abc/def/"},
+		{"/a/b/c//", "/a/b/c/"},
+		{"/abc//def//ghi", "/abc/def/ghi"},
+		{"//abc", "/abc"},
+		{"///abc", "/abc"},
+		{"//abc//", "/abc/"},
+
+		// Remove . elements
+		{".", "/"},
+		{"./", "/"},
+		{"/abc/./def", "/abc/def"},
+		{"/./abc/def", "/abc/def"},
+		{"/abc/.", "/abc/"},
+
+		// Remove .. elements
+		{"..", "/"},
+		{"../", "/"},
+		{"../../", "/"},
+		{"../..", "/"},
+		{"../../abc", "/abc"},
+		{"/abc/def/ghi/../jkl", "/abc/def/jkl"},
+		{"/abc/def/../ghi/../jkl", "/abc/jkl"},
+		{"/abc/def/..", "/abc"},
+		{"/abc/def/../..", "/"},
+		{"/abc/def/../../..", "/"},
+		{"/abc/def/../../..", "/"},
+		{"/abc/def/../../../ghi/jkl/../../../mno", "/mno"},
+
+		// Combinations
+		{"abc/./../def", "/def"},
+		{"abc//./../def", "/def"},
+		{"abc/../../././../def", "/def"},
+	}
+
+	for _, test := range tests {
+		Equal(t, CleanPath(test.path), test.result)
+		Equal(t, CleanPath(test.result), test.result)
+	}
+}
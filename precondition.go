@@ -0,0 +1,138 @@
+package lars
+
+import (
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conditional request headers
+const (
+	IfMatch           = "If-Match"
+	IfNoneMatch       = "If-None-Match"
+	IfModifiedSince   = "If-Modified-Since"
+	IfUnmodifiedSince = "If-Unmodified-Since"
+	LastModified      = "Last-Modified"
+)
+
+// NewETag returns a strong ETag, including the quotes, generated from a hash of b.
+func NewETag(b []byte) string {
+
+	h := fnv.New64a()
+	h.Write(b)
+
+	return `"` + strconv.FormatInt(int64(len(b)), 36) + "-" + strconv.FormatUint(h.Sum64(), 36) + `"`
+}
+
+// EvaluatePreconditions evaluates the requests conditional headers against the current
+// etag and modtime of the resource, following the order of RFC 7232 section 6, returning
+// the status code, http.StatusNotModified or http.StatusPreconditionFailed, the request
+// should be answered with or 0 when the request should proceed. A blank etag or zero
+// modtime means the resource has none and the related headers are ignored.
+func EvaluatePreconditions(r *http.Request, etag string, modtime time.Time) int {
+
+	if im := r.Header.Get(IfMatch); im != blank {
+
+		if !matchETag(im, etag, false) {
+			return http.StatusPreconditionFailed
+		}
+
+	} else if ius := r.Header.Get(IfUnmodifiedSince); ius != blank && !modtime.IsZero() {
+
+		if t, err := http.ParseTime(ius); err == nil && modtime.Truncate(time.Second).After(t) {
+			return http.StatusPreconditionFailed
+		}
+	}
+
+	safe := r.Method == GET || r.Method == HEAD
+
+	if inm := r.Header.Get(IfNoneMatch); inm != blank {
+
+		if matchETag(inm, etag, true) {
+
+			if safe {
+				return http.StatusNotModified
+			}
+
+			return http.StatusPreconditionFailed
+		}
+
+	} else if ims := r.Header.Get(IfModifiedSince); ims != blank && safe && !modtime.IsZero() {
+
+		if t, err := http.ParseTime(ims); err == nil && !modtime.Truncate(time.Second).After(t) {
+			return http.StatusNotModified
+		}
+	}
+
+	return 0
+}
+
+// CheckPreconditions sets the ETag and Last-Modified headers, when provided, and
+// evaluates the requests conditional headers using EvaluatePreconditions; writing a
+// 304 Not Modified or 412 Precondition Failed and returning true when the request
+// has been answered and the handler should return.
+func (c *Ctx) CheckPreconditions(etag string, modtime time.Time) bool {
+
+	h := c.response.Header()
+
+	if etag != blank {
+		h.Set(ETag, etag)
+	}
+
+	if !modtime.IsZero() {
+		h.Set(LastModified, modtime.UTC().Format(http.TimeFormat))
+	}
+
+	code := EvaluatePreconditions(c.request, etag, modtime)
+	if code == 0 {
+		return false
+	}
+
+	if code == http.StatusNotModified {
+		h.Del(ContentType)
+		h.Del(ContentLength)
+	}
+
+	c.response.WriteHeader(code)
+
+	return true
+}
+
+// matchETag returns if etag matches any of the ETags in the If-Match or If-None-Match
+// header value, or it's "*" and the resource has an etag. The weak comparison
+// ignores the W/ prefix while the strong comparison never matches weak ETags.
+func matchETag(header string, etag string, weak bool) bool {
+
+	if etag == blank {
+		return false
+	}
+
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	} else if strings.HasPrefix(etag, "W/") {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+
+		candidate = strings.TrimSpace(candidate)
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
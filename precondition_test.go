@@ -0,0 +1,111 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestCheckPreconditions(t *testing.T) {
+
+	modtime := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	etag := `"v2"`
+
+	l := New()
+	handler := func(c Context) {
+
+		if c.CheckPreconditions(etag, modtime) {
+			return
+		}
+
+		c.Text(http.StatusOK, "ok")
+	}
+	l.Get("/article", handler)
+	l.Put("/article", handler)
+	l.Get("/none", func(c Context) {
+
+		if c.CheckPreconditions(blank, time.Time{}) {
+			return
+		}
+
+		c.Text(http.StatusOK, "ok")
+	})
+
+	hf := l.Serve()
+
+	tests := []struct {
+		method  string
+		path    string
+		headers map[string]string
+		code    int
+	}{
+		{GET, "/article", nil, http.StatusOK},
+		{GET, "/article", map[string]string{IfNoneMatch: `"v2"`}, http.StatusNotModified},
+		{GET, "/article", map[string]string{IfNoneMatch: `W/"v2"`}, http.StatusNotModified},
+		{GET, "/article", map[string]string{IfNoneMatch: `"v1"`}, http.StatusOK},
+		{GET, "/article", map[string]string{IfNoneMatch: `*`}, http.StatusNotModified},
+		{GET, "/article", map[string]string{IfModifiedSince: modtime.Format(http.TimeFormat)}, http.StatusNotModified},
+		{GET, "/article", map[string]string{IfModifiedSince: modtime.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{GET, "/article", map[string]string{IfModifiedSince: "invalid"}, http.StatusOK},
+		// If-None-Match takes precedence over If-Modified-Since
+		{GET, "/article", map[string]string{IfNoneMatch: `"v1"`, IfModifiedSince: modtime.Format(http.TimeFormat)}, http.StatusOK},
+		{PUT, "/article", nil, http.StatusOK},
+		{PUT, "/article", map[string]string{IfMatch: `"v2"`}, http.StatusOK},
+		{PUT, "/article", map[string]string{IfMatch: `"v1", "v2"`}, http.StatusOK},
+		{PUT, "/article", map[string]string{IfMatch: `"v1"`}, http.StatusPreconditionFailed},
+		{PUT, "/article", map[string]string{IfMatch: `W/"v2"`}, http.StatusPreconditionFailed},
+		{PUT, "/article", map[string]string{IfMatch: `*`}, http.StatusOK},
+		{PUT, "/article", map[string]string{IfUnmodifiedSince: modtime.Format(http.TimeFormat)}, http.StatusOK},
+		{PUT, "/article", map[string]string{IfUnmodifiedSince: modtime.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusPreconditionFailed},
+		// If-Match takes precedence over If-Unmodified-Since
+		{PUT, "/article", map[string]string{IfMatch: `"v2"`, IfUnmodifiedSince: modtime.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{PUT, "/article", map[string]string{IfNoneMatch: `*`}, http.StatusPreconditionFailed},
+		{PUT, "/article", map[string]string{IfModifiedSince: modtime.Format(http.TimeFormat)}, http.StatusOK},
+		{GET, "/none", map[string]string{IfNoneMatch: `*`}, http.StatusOK},
+		{GET, "/none", map[string]string{IfMatch: `*`}, http.StatusPreconditionFailed},
+		{GET, "/none", map[string]string{IfModifiedSince: modtime.Format(http.TimeFormat)}, http.StatusOK},
+	}
+
+	for i, tt := range tests {
+
+		r, _ := http.NewRequest(tt.method, tt.path, nil)
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		hf.ServeHTTP(w, r)
+
+		if w.Code != tt.code {
+			t.Errorf("test %d: %s %s %v expected %d got %d", i, tt.method, tt.path, tt.headers, tt.code, w.Code)
+		}
+	}
+
+	r, _ := http.NewRequest(GET, "/article", nil)
+	w := httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Header().Get(ETag), etag)
+	Equal(t, w.Header().Get(LastModified), "Sat, 02 Jan 2016 15:04:05 GMT")
+
+	r, _ = http.NewRequest(GET, "/none", nil)
+	w = httptest.NewRecorder()
+	hf.ServeHTTP(w, r)
+	Equal(t, w.Header().Get(ETag), blank)
+	Equal(t, w.Header().Get(LastModified), blank)
+
+	Equal(t, NewETag([]byte("data")), NewETag([]byte("data")))
+	NotEqual(t, NewETag([]byte("data")), NewETag([]byte("date")))
+}
@@ -0,0 +1,198 @@
+package lars
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server-Sent Events headers and content type
+const (
+	TextEventStream = "text/event-stream"
+	LastEventID     = "Last-Event-ID"
+)
+
+// ErrSSEClosed is returned when sending using a closed SSEWriter
+var ErrSSEClosed = errors.New("lars: SSEWriter closed")
+
+// SSEWriter writes Server-Sent Events to the response, see Ctx.SSE.
+// It's safe for concurrent use, but must not be used once the handler returns.
+type SSEWriter struct {
+	c        *Ctx
+	done     <-chan struct{}
+	m        sync.Mutex
+	buff     bytes.Buffer
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// SSE sets the Server-Sent Events headers, writes the 200 OK status and returns an
+// SSEWriter for sending events. Sending stops, returning the contexts error, once
+// the request context is done i.e. the client has disconnected.
+// NOTE: call Close before the handler returns when using KeepAlive.
+func (c *Ctx) SSE() *SSEWriter {
+
+	h := c.response.Header()
+	h.Set(ContentType, TextEventStream)
+	h.Set(CacheControl, "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+
+	c.response.WriteHeader(http.StatusOK)
+	c.response.Flush()
+
+	return &SSEWriter{
+		c:    c,
+		done: c.Done(),
+		stop: make(chan struct{}),
+	}
+}
+
+// LastEventID returns the ID of the last event the client received, sent
+// by the client when reconnecting, so that missed events can be resent.
+func (s *SSEWriter) LastEventID() string {
+	return s.c.request.Header.Get(LastEventID)
+}
+
+// Done returns a channel that's closed when the request context is done.
+func (s *SSEWriter) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes and flushes a single event; event, id and retry are optional and
+// omitted when blank or 0, data spanning multiple lines is sent as multiple data
+// fields. Line breaks are removed from event and id as they'd end the field.
+func (s *SSEWriter) Send(event string, id string, data string, retry time.Duration) error {
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if event != blank {
+		s.writeField("event", stripLineBreaks(event))
+	}
+
+	if id != blank {
+		s.writeField("id", stripLineBreaks(id))
+	}
+
+	if retry > 0 {
+		s.writeField("retry", strconv.FormatInt(int64(retry/time.Millisecond), 10))
+	}
+
+	data = strings.Replace(data, "\r\n", "\n", -1)
+	data = strings.Replace(data, "\r", "\n", -1)
+
+	for _, line := range strings.Split(data, "\n") {
+		s.writeField("data", line)
+	}
+
+	s.buff.WriteByte('\n')
+
+	return s.flush()
+}
+
+// Comment writes and flushes a comment, ignored by clients, i.e. to keep the
+// connection alive through proxies.
+func (s *SSEWriter) Comment(comment string) error {
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.buff.WriteByte(':')
+
+	if comment != blank {
+		s.buff.WriteByte(' ')
+		s.buff.WriteString(stripLineBreaks(comment))
+	}
+
+	s.buff.WriteString("\n\n")
+
+	return s.flush()
+}
+
+// KeepAlive sends an empty comment every interval, until the request context is
+// done or Close is called, so idle connections aren't closed by proxies.
+func (s *SSEWriter) KeepAlive(interval time.Duration) {
+
+	s.wg.Add(1)
+
+	go func() {
+
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if s.Comment(blank) != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Close stops any KeepAlive, waiting for it to finish, after which
+// nothing more is written to the response.
+func (s *SSEWriter) Close() {
+
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	s.wg.Wait()
+}
+
+func (s *SSEWriter) writeField(name string, value string) {
+	s.buff.WriteString(name)
+	s.buff.WriteString(": ")
+	s.buff.WriteString(value)
+	s.buff.WriteByte('\n')
+}
+
+// flush writes the buffered event, unless closed or the request context is done
+func (s *SSEWriter) flush() (err error) {
+
+	defer s.buff.Reset()
+
+	select {
+	case <-s.done:
+		return s.c.Err()
+	case <-s.stop:
+		return ErrSSEClosed
+	default:
+	}
+
+	if _, err = s.c.response.Write(s.buff.Bytes()); err != nil {
+		return
+	}
+
+	s.c.response.Flush()
+
+	return
+}
+
+func stripLineBreaks(s string) string {
+
+	if strings.IndexAny(s, "\r\n") == -1 {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,81 @@
+package lars
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestSSE(t *testing.T) {
+
+	l := New()
+	l.Get("/events", func(c Context) {
+
+		sse := c.SSE()
+		defer sse.Close()
+
+		Equal(t, sse.Send("", "", "hello", 0), nil)
+		Equal(t, sse.Send("update", sse.LastEventID()+"-1", "line 1\nline 2\r\nline 3", 3*time.Second), nil)
+		Equal(t, sse.Send("bad\nevent", "bad\rid", "", 0), nil)
+		Equal(t, sse.Comment("ping"), nil)
+		Equal(t, sse.Comment(""), nil)
+
+		sse.Close()
+		Equal(t, sse.Send("", "", "closed", 0), ErrSSEClosed)
+	})
+
+	r, _ := http.NewRequest(GET, "/events", nil)
+	r.Header.Set(LastEventID, "41")
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), TextEventStream)
+	Equal(t, w.Header().Get(CacheControl), "no-cache")
+	Equal(t, w.Flushed, true)
+	Equal(t, w.Body.String(), "data: hello\n\n"+
+		"event: update\nid: 41-1\nretry: 3000\ndata: line 1\ndata: line 2\ndata: line 3\n\n"+
+		"event: badevent\nid: badid\ndata: \n\n"+
+		": ping\n\n"+
+		":\n\n")
+}
+
+func TestSSEKeepAlive(t *testing.T) {
+
+	l := New()
+	l.Get("/events", func(c Context) {
+
+		sse := c.SSE()
+		sse.KeepAlive(5 * time.Millisecond)
+
+		time.Sleep(30 * time.Millisecond)
+
+		sse.Close()
+		sse.Close()
+
+		Equal(t, sse.Send("", "", "done", 0), ErrSSEClosed)
+	})
+
+	r, _ := http.NewRequest(GET, "/events", nil)
+	w := httptest.NewRecorder()
+	l.Serve().ServeHTTP(w, r)
+
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, strings.Count(w.Body.String(), ":\n\n") > 1, true)
+	Equal(t, strings.Replace(w.Body.String(), ":\n\n", "", -1), "")
+}
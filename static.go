@@ -0,0 +1,329 @@
+package lars
+
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticOptions configures how files are served by Static.
+type StaticOptions struct {
+
+	// Index is the file served for a directory, default "index.html"
+	Index string
+
+	// Browse lists the contents of directories that have no index file
+	Browse bool
+
+	// SPA serves the root index file for any path that isn't found,
+	// for single page applications doing their own client side routing
+	SPA bool
+
+	// Precompressed serves a ".br" or ".gz" sibling of the requested file,
+	// when present and accepted by the client, with the matching Content-Encoding
+	Precompressed bool
+
+	// MaxAge, when > 0, sets the Cache-Control max-age of files served
+	MaxAge time.Duration
+}
+
+// precompressedEncodings are the precompressed file extensions served by
+// Static, in order of preference, and their Content-Encoding
+var precompressedEncodings = []struct{ encoding, ext string }{
+	{"br", ".br"},
+	{Gzip, ".gz"},
+}
+
+// staticServer serves files from root for Static and File routes
+type staticServer struct {
+	group *routeGroup
+	root  http.FileSystem
+	opts  StaticOptions
+}
+
+// Static registers GET and HEAD routes serving the files, and directories, within root
+// under prefix using a *wildcard route. Requests are cleaned to prevent escaping root,
+// ETag, Last-Modified, conditional and Range requests are handled by http.ServeContent.
+func (g *routeGroup) Static(prefix string, root http.FileSystem, opts StaticOptions) IRoute {
+
+	if opts.Index == blank {
+		opts.Index = "index.html"
+	}
+
+	s := &staticServer{group: g, root: root, opts: opts}
+	path := strings.TrimRight(prefix, basePath) + "/*"
+
+	g.Head(path, s.serveStatic)
+
+	return g.Get(path, s.serveStatic)
+}
+
+// File registers GET and HEAD routes serving the single file filename at path.
+func (g *routeGroup) File(path string, filename string) IRoute {
+
+	dir, name := "."+string(os.PathSeparator), filename
+
+	if idx := strings.LastIndexAny(filename, `/\`); idx != -1 {
+		dir, name = filename[:idx+1], filename[idx+1:]
+	}
+
+	s := &staticServer{group: g, root: http.Dir(dir)}
+	name = basePath + name
+
+	fn := func(c Context) {
+		s.serve(c, name, false)
+	}
+
+	g.Head(path, fn)
+
+	return g.Get(path, fn)
+}
+
+func (s *staticServer) serveStatic(c Context) {
+	s.serve(c, path.Clean(basePath+c.Param(WildcardParam)), true)
+}
+
+func (s *staticServer) serve(c Context, name string, allowDir bool) {
+
+	f, err := s.root.Open(name)
+	if err != nil {
+
+		if s.opts.SPA && os.IsNotExist(err) {
+			s.serveIndex(c)
+			return
+		}
+
+		s.staticError(c, err)
+		return
+	}
+
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		s.staticError(c, err)
+		return
+	}
+
+	if !fi.IsDir() {
+		s.serveFile(c, name, f, fi)
+		return
+	}
+
+	if !allowDir {
+		s.staticError(c, os.ErrNotExist)
+		return
+	}
+
+	r := c.Request()
+
+	// redirect to the canonical directory path so relative links work
+	if !strings.HasSuffix(r.URL.Path, basePath) {
+
+		u := c.BaseContext().mountPath + r.URL.EscapedPath() + basePath
+		if r.URL.RawQuery != blank {
+			u += "?" + r.URL.RawQuery
+		}
+
+		http.Redirect(c.Response(), r, u, http.StatusMovedPermanently)
+		return
+	}
+
+	index := path.Join(name, s.opts.Index)
+
+	if ff, err := s.root.Open(index); err == nil {
+
+		defer ff.Close()
+
+		if ffi, err := ff.Stat(); err == nil && !ffi.IsDir() {
+			s.serveFile(c, index, ff, ffi)
+			return
+		}
+	}
+
+	if s.opts.Browse {
+		dirList(c, f)
+		return
+	}
+
+	if s.opts.SPA {
+		s.serveIndex(c)
+		return
+	}
+
+	s.staticError(c, os.ErrNotExist)
+}
+
+// serveIndex serves the root index file as the SPA fallback
+func (s *staticServer) serveIndex(c Context) {
+
+	name := basePath + s.opts.Index
+
+	f, err := s.root.Open(name)
+	if err != nil {
+		s.staticError(c, err)
+		return
+	}
+
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		s.staticError(c, os.ErrNotExist)
+		return
+	}
+
+	// the fallback must be revalidated as the path may later exist
+	c.Response().Header().Set(CacheControl, "no-cache")
+
+	s.serveFile(c, name, f, fi)
+}
+
+func (s *staticServer) serveFile(c Context, name string, f http.File, fi os.FileInfo) {
+
+	h := c.Response().Header()
+	content, modtime, size := http.File(f), fi.ModTime(), fi.Size()
+	etagSuffix := blank
+
+	if s.opts.Precompressed {
+
+		h.Add(Vary, AcceptEncoding)
+
+		accept := c.Request().Header.Get(AcceptEncoding)
+
+		for _, pe := range precompressedEncodings {
+
+			if !acceptsEncoding(accept, pe.encoding) {
+				continue
+			}
+
+			cf, err := s.root.Open(name + pe.ext)
+			if err != nil {
+				continue
+			}
+
+			defer cf.Close()
+
+			cfi, err := cf.Stat()
+			if err != nil || cfi.IsDir() {
+				continue
+			}
+
+			// content type must be determined from the original file as
+			// sniffing the compressed content would be incorrect
+			h.Set(ContentType, detectContentType(name))
+			h.Set(ContentEncoding, pe.encoding)
+
+			content, modtime, size = cf, cfi.ModTime(), cfi.Size()
+			etagSuffix = "-" + pe.encoding
+			break
+		}
+	}
+
+	h.Set(ETag, `"`+strconv.FormatInt(modtime.UnixNano(), 36)+"-"+strconv.FormatInt(size, 36)+etagSuffix+`"`)
+
+	if s.opts.MaxAge > 0 && h.Get(CacheControl) == blank {
+		h.Set(CacheControl, "public, max-age="+strconv.FormatInt(int64(s.opts.MaxAge/time.Second), 10))
+	}
+
+	http.ServeContent(c.Response(), c.Request(), name, modtime, content)
+}
+
+// acceptsEncoding returns true if the Accept-Encoding header accepts
+// the encoding, either by name or *, with a q-value > 0
+func acceptsEncoding(accept string, encoding string) bool {
+
+	wildcard := false
+
+	for _, v := range ParseAccept(accept) {
+
+		switch v.Value {
+		case encoding:
+			return v.Q > 0
+		case "*":
+			wildcard = v.Q > 0
+		}
+	}
+
+	return wildcard
+}
+
+type fileInfos []os.FileInfo
+
+func (f fileInfos) Len() int           { return len(f) }
+func (f fileInfos) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f fileInfos) Less(i, j int) bool { return f[i].Name() < f[j].Name() }
+
+// dirList renders a simple HTML listing of the directories contents
+func dirList(c Context, f http.File) {
+
+	files, err := f.Readdir(-1)
+	if err != nil {
+		http.Error(c.Response(), "Error reading directory", http.StatusInternalServerError)
+		return
+	}
+
+	sort.Sort(fileInfos(files))
+
+	buff := new(bytes.Buffer)
+	buff.WriteString("<pre>\n")
+
+	for _, fi := range files {
+
+		name := fi.Name()
+		if fi.IsDir() {
+			name += basePath
+		}
+
+		u := url.URL{Path: name}
+
+		buff.WriteString("<a href=\"")
+		buff.WriteString(u.String())
+		buff.WriteString("\">")
+		buff.WriteString(html.EscapeString(name))
+		buff.WriteString("</a>\n")
+	}
+
+	buff.WriteString("</pre>\n")
+
+	c.Response().Header().Set(ContentType, TextHTMLCharsetUTF8)
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Write(buff.Bytes())
+}
+
+// staticError writes the status code matching the file system error, not
+// found errors are handled by the 404 handlers of the group with the longest
+// prefix matching the request, or LARS.Register404 when none match.
+func (s *staticServer) staticError(c Context, err error) {
+
+	switch {
+	case os.IsNotExist(err):
+		ctx := c.BaseContext()
+		l := ctx.lars
+
+		ctx.handlers = l.http404
+
+		if gh := l.findGroupHandlers(s.group.host, l.routingPath(ctx.request.URL), true); gh != nil {
+
+			// skip the middleware shared with the static routes group that has
+			// already run before reaching this handler
+			ctx.handlers = gh.notFound[min(sharedMiddleware(s.group, gh.group), ctx.index):]
+		}
+
+		ctx.index = -1
+		ctx.Next()
+
+	case os.IsPermission(err):
+		http.Error(c.Response(), http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+	default:
+		http.Error(c.Response(), http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
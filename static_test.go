@@ -0,0 +1,162 @@
+package lars
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "gopkg.in/go-playground/assert.v1"
+)
+
+// NOTES:
+// - Run "go test" to run tests
+// - Run "gocov test | gocov report" to report on test converage by file
+// - Run "gocov test | gocov annotate -" to report on all code and functions, those ,marked with "MISS" were never called
+//
+// or
+//
+// -- may be a good idea to change to output path to somewherelike /tmp
+// go test -coverprofile cover.out && go tool cover -html=cover.out -o cover.html
+//
+
+func TestStatic(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "lars-static")
+	Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"index.html":          "<h1>home</h1>",
+		"app.js":              "console.log('app');",
+		"app.js.gz":           "gzipped",
+		"app.js.br":           "brotli",
+		"docs/readme.txt":     "readme",
+		"docs/sub/index.html": "<h1>sub</h1>",
+		"private/secret.txt":  "secret",
+	}
+
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		Equal(t, os.MkdirAll(filepath.Dir(name), 0755), nil)
+		Equal(t, ioutil.WriteFile(name, []byte(content), 0644), nil)
+	}
+
+	l := New()
+	l.Static("/assets/", http.Dir(dir), StaticOptions{MaxAge: time.Hour, Browse: true})
+	l.Static("/precompressed", http.Dir(dir), StaticOptions{Precompressed: true})
+	l.Static("/app", http.Dir(dir), StaticOptions{SPA: true})
+	l.Group("/private").Static("/files", http.Dir(filepath.Join(dir, "private")), StaticOptions{})
+	l.File("/readme", filepath.Join(dir, "docs", "readme.txt"))
+
+	hf := l.Serve()
+
+	w := serveRequest(GET, "/assets/app.js", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "console.log('app');")
+	Equal(t, w.Header().Get(CacheControl), "public, max-age=3600")
+	Equal(t, w.Header().Get(ContentEncoding), "")
+	NotEqual(t, w.Header().Get(ETag), "")
+	NotEqual(t, w.Header().Get("Last-Modified"), "")
+
+	etag := w.Header().Get(ETag)
+
+	w = serveRequest(GET, "/assets/app.js", map[string]string{"If-None-Match": etag}, hf)
+	Equal(t, w.Code, http.StatusNotModified)
+
+	w = serveRequest(GET, "/assets/app.js", map[string]string{"Range": "bytes=0-6"}, hf)
+	Equal(t, w.Code, http.StatusPartialContent)
+	Equal(t, w.Body.String(), "console")
+
+	w = serveRequest(HEAD, "/assets/app.js", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.Len(), 0)
+
+	w = serveRequest(GET, "/assets/", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "<h1>home</h1>")
+
+	w = serveRequest(GET, "/assets/docs?a=b", nil, hf)
+	Equal(t, w.Code, http.StatusMovedPermanently)
+	Equal(t, w.Header().Get(Location), "/assets/docs/?a=b")
+
+	w = serveRequest(GET, "/assets/docs/", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Header().Get(ContentType), TextHTMLCharsetUTF8)
+	Equal(t, w.Body.String(), "<pre>\n<a href=\"readme.txt\">readme.txt</a>\n<a href=\"sub/\">sub/</a>\n</pre>\n")
+
+	w = serveRequest(GET, "/assets/docs/sub/", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "<h1>sub</h1>")
+
+	w = serveRequest(GET, "/assets/missing.js", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveRequest(GET, "/assets/../../etc/passwd", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveRequest(GET, "/private/files/..%2fapp.js", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveRequest(GET, "/private/files/secret.txt", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "secret")
+
+	w = serveRequest(GET, "/private/files/", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveRequest(GET, "/precompressed/app.js", map[string]string{AcceptEncoding: "gzip, br"}, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "brotli")
+	Equal(t, w.Header().Get(ContentEncoding), "br")
+	MatchRegex(t, w.Header().Get(ContentType), "javascript")
+	Equal(t, w.Header().Get(Vary), AcceptEncoding)
+	MatchRegex(t, w.Header().Get(ETag), "-br\"$")
+
+	w = serveRequest(GET, "/precompressed/app.js", map[string]string{AcceptEncoding: "gzip, br;q=0"}, hf)
+	Equal(t, w.Body.String(), "gzipped")
+	Equal(t, w.Header().Get(ContentEncoding), Gzip)
+
+	w = serveRequest(GET, "/precompressed/app.js", map[string]string{AcceptEncoding: "*;q=0"}, hf)
+	Equal(t, w.Body.String(), "console.log('app');")
+	Equal(t, w.Header().Get(ContentEncoding), "")
+
+	w = serveRequest(GET, "/precompressed/docs/readme.txt", map[string]string{AcceptEncoding: "*"}, hf)
+	Equal(t, w.Body.String(), "readme")
+	Equal(t, w.Header().Get(ContentEncoding), "")
+
+	w = serveRequest(GET, "/app/users/13", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "<h1>home</h1>")
+	Equal(t, w.Header().Get(CacheControl), "no-cache")
+
+	w = serveRequest(GET, "/app/app.js", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "console.log('app');")
+
+	w = serveRequest(GET, "/readme", nil, hf)
+	Equal(t, w.Code, http.StatusOK)
+	Equal(t, w.Body.String(), "readme")
+
+	l2 := New()
+	l2.File("/missing", filepath.Join(dir, "missing.txt"))
+	l2.File("/dir", filepath.Join(dir, "docs"))
+
+	hf = l2.Serve()
+
+	w = serveRequest(GET, "/missing", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	w = serveRequest(GET, "/dir", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+
+	l3 := New()
+	l3.Static("/", http.Dir(filepath.Join(dir, "missing")), StaticOptions{SPA: true})
+
+	hf = l3.Serve()
+
+	w = serveRequest(GET, "/anything", nil, hf)
+	Equal(t, w.Code, http.StatusNotFound)
+}
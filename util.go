@@ -1,11 +1,16 @@
 package lars
 
 import (
+	"io"
 	"mime"
 	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strings"
+	"time"
 )
 
 // NativeChainHandler is used in native handler chain middleware
@@ -37,6 +42,84 @@ func detectContentType(filename string) (t string) {
 	return
 }
 
+// modTime returns the modification time of r if it has a Stat method, i.e. *os.File
+func modTime(r io.Reader) (t time.Time) {
+
+	if st, ok := r.(interface {
+		Stat() (os.FileInfo, error)
+	}); ok {
+		if fi, err := st.Stat(); err == nil {
+			t = fi.ModTime()
+		}
+	}
+
+	return
+}
+
+// contentDisposition returns the Content-Disposition header value, as per RFC 6266,
+// with the filename quoted and, when it contains non ASCII characters, both an ASCII
+// fallback and the UTF-8 percent encoded filename* parameter of RFC 5987.
+func contentDisposition(disposition string, filename string) string {
+
+	buff := make([]byte, 0, len(disposition)+len(filename)+14)
+	buff = append(buff, disposition...)
+	buff = append(buff, `; filename="`...)
+
+	ascii := true
+
+	for _, r := range filename {
+
+		switch {
+		case r == '"' || r == '\\':
+			buff = append(buff, '\\', byte(r))
+		case r < ' ' || r == 0x7f:
+			buff = append(buff, '_')
+		case r > 0x7f:
+			ascii = false
+			buff = append(buff, '_')
+		default:
+			buff = append(buff, byte(r))
+		}
+	}
+
+	buff = append(buff, '"')
+
+	if ascii {
+		return string(buff)
+	}
+
+	buff = append(buff, "; filename*=UTF-8''"...)
+
+	for i := 0; i < len(filename); i++ {
+
+		if b := filename[i]; isAttrChar(b) {
+			buff = append(buff, b)
+		} else {
+			buff = append(buff, '%', hexUpper[b>>4], hexUpper[b&0x0f])
+		}
+	}
+
+	return string(buff)
+}
+
+const hexUpper = "0123456789ABCDEF"
+
+// isAttrChar returns if the byte is an attr-char of RFC 5987 which
+// doesn't need to be percent encoded.
+func isAttrChar(b byte) bool {
+
+	if b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' {
+		return true
+	}
+
+	switch b {
+	case '!', '#', '$', '&', '+', '-', '.', '^', '_', '`', '|', '~':
+		return true
+	}
+
+	return false
+}
+
 // wrapHandler wraps Handler type
 func (l *LARS) wrapHandler(h Handler) HandlerFunc {
 
@@ -47,6 +130,13 @@ func (l *LARS) wrapHandler(h Handler) HandlerFunc {
 	case func(Context):
 		return h
 
+	case func(Context) error:
+		return func(c Context) {
+			if err := h(c); err != nil {
+				l.handleError(c, err)
+			}
+		}
+
 	case http.Handler, http.HandlerFunc:
 		return func(c Context) {
 
@@ -133,6 +223,136 @@ func (l *LARS) redirect(method string, to string) (handlers HandlersChain) {
 	return
 }
 
+// fixedURL returns the URL, with the fixed path prefixed by the escaped mountPath,
+// to redirect to; the fixed path is escaped when routing using the raw path.
+func (l *LARS) fixedURL(u *url.URL, mountPath string, fixed string) string {
+
+	if l.useRawPath {
+		fixed = escapeRoutingPath(fixed)
+	} else {
+		fixed = escapePath(fixed)
+	}
+
+	fu := *u
+	fu.Path, fu.RawPath = unescapePath(mountPath+fixed), mountPath+fixed
+
+	return fu.String()
+}
+
+// routingPath returns the path routes are matched against
+func (l *LARS) routingPath(u *url.URL) string {
+
+	if l.useRawPath {
+		return rawRoutingPath(u.EscapedPath())
+	}
+
+	return u.Path
+}
+
+// rawRoutingPath returns the escaped path with every escape, except encoded
+// slashes and percents, unescaped so that static route segments match while
+// encoded slashes remain part of the param values.
+func rawRoutingPath(escaped string) string {
+
+	if strings.IndexByte(escaped, '%') == -1 {
+		return escaped
+	}
+
+	b := make([]byte, 0, len(escaped))
+
+	for i := 0; i < len(escaped); i++ {
+
+		if escaped[i] == '%' && i+2 < len(escaped) && isHex(escaped[i+1]) && isHex(escaped[i+2]) {
+
+			if c := unhex(escaped[i+1])<<4 | unhex(escaped[i+2]); c != '/' && c != '%' {
+				b = append(b, c)
+				i += 2
+				continue
+			}
+		}
+
+		b = append(b, escaped[i])
+	}
+
+	return string(b)
+}
+
+// escapeRoutingPath escapes a path returned by rawRoutingPath, leaving the
+// encoded slashes and percents as is.
+func escapeRoutingPath(p string) string {
+
+	parts := strings.Split(p, "%")
+
+	for i := range parts {
+		parts[i] = escapePath(parts[i])
+	}
+
+	return strings.Join(parts, "%")
+}
+
+// escapePath escapes a path for use in a URL
+func escapePath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	}
+
+	return c - 'A' + 10
+}
+
+// splitMountPath splits the escaped request path into the escaped prefix a router is
+// mounted under and the escaped remainder matching the unescaped wildcard value rest.
+func splitMountPath(escaped string, rest string) (prefix string, raw string) {
+
+	rest = basePath + rest
+
+	for i := len(escaped) - 1; i >= 0; i-- {
+
+		if escaped[i] == '/' && unescapePath(escaped[i:]) == rest {
+			return escaped[:i], escaped[i:]
+		}
+	}
+
+	return strings.TrimSuffix(escaped, rest), blank
+}
+
+// unescapePath unescapes a path segment, unlike url.QueryUnescape
+// '+' is left as is; the value is returned unchanged if invalid.
+func unescapePath(s string) string {
+
+	if strings.IndexByte(s, '%') == -1 {
+		return s
+	}
+
+	if u, err := url.QueryUnescape(strings.Replace(s, "+", "%2B", -1)); err == nil {
+		return u
+	}
+
+	return s
+}
+
+func findParam(params Params, key string) (string, bool) {
+
+	for _, p := range params {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+
+	return blank, false
+}
+
 func min(a, b int) int {
 
 	if a <= b {
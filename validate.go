@@ -0,0 +1,80 @@
+package lars
+
+import (
+	"bytes"
+)
+
+// statusUnprocessableEntity is defined here as http.StatusUnprocessableEntity
+// isn't available prior to go1.7
+const statusUnprocessableEntity = 422
+
+// Validator validates decoded request data, see RegisterValidator.
+// Validate should return ValidationErrors when v fails validation, so they can be
+// rendered as a 422 Unprocessable Entity, any other error is treated as a failure
+// to validate.
+type Validator interface {
+	Validate(v interface{}) error
+}
+
+// FieldError describes why a single field failed validation.
+type FieldError struct {
+	Field   string `json:"field" xml:"name"`
+	Rule    string `json:"rule,omitempty" xml:"rule,omitempty"`
+	Message string `json:"message" xml:"message"`
+}
+
+// ValidationErrors is the error returned by a Validator when validation fails,
+// it's rendered as a 422 Unprocessable Entity by the DefaultErrorHandler.
+type ValidationErrors []*FieldError
+
+var _ error = ValidationErrors(nil)
+
+// Error returns each field error on a separate line.
+func (ve ValidationErrors) Error() string {
+
+	buff := new(bytes.Buffer)
+
+	for i, fe := range ve {
+
+		if i > 0 {
+			buff.WriteByte('\n')
+		}
+
+		buff.WriteString(fe.Field)
+		buff.WriteString(": ")
+		buff.WriteString(fe.Message)
+	}
+
+	return buff.String()
+}
+
+// httpError returns the *HTTPError the validation errors are rendered as.
+func (ve ValidationErrors) httpError() *HTTPError {
+
+	he := NewHTTPError(statusUnprocessableEntity, "validation_failed", "Unprocessable Entity")
+	he.Fields = ve
+
+	return he
+}
+
+// RegisterValidator registers the Validator run by DecodeAndValidate once the
+// request has been decoded.
+func (l *LARS) RegisterValidator(v Validator) {
+	l.validator = v
+}
+
+// DecodeAndValidate decodes the request exactly like Decode and then, if one
+// has been registered, validates v using the registered Validator; returning
+// ValidationErrors if validation fails.
+func (c *Ctx) DecodeAndValidate(includeFormQueryParams bool, maxMemory int64, v interface{}) error {
+
+	if err := c.parent.Decode(includeFormQueryParams, maxMemory, v); err != nil {
+		return err
+	}
+
+	if c.lars.validator == nil {
+		return nil
+	}
+
+	return c.lars.validator.Validate(v)
+}
@@ -0,0 +1,112 @@
+package lars
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RouteError describes a route which failed to register, when collecting registration
+// errors, including the existing route it conflicts with if known.
+type RouteError struct {
+	Method              string
+	Path                string
+	HandlerName         string
+	ConflictPath        string
+	ConflictHandlerName string
+	Message             string
+}
+
+var _ error = new(RouteError)
+
+// Error returns the route, the route it conflicts with and why it failed to register.
+func (e *RouteError) Error() string {
+
+	buff := new(bytes.Buffer)
+	buff.WriteString("lars: ")
+
+	if e.Method != blank {
+		buff.WriteString(e.Method)
+		buff.WriteByte(' ')
+	}
+
+	buff.WriteString(e.Path)
+
+	if e.HandlerName != blank {
+		buff.WriteString(" (" + e.HandlerName + ")")
+	}
+
+	if e.ConflictPath != blank {
+		buff.WriteString(" conflicts with " + e.ConflictPath)
+
+		if e.ConflictHandlerName != blank {
+			buff.WriteString(" (" + e.ConflictHandlerName + ")")
+		}
+	}
+
+	buff.WriteString(": " + e.Message)
+
+	return buff.String()
+}
+
+// RegistrationErrors is the error returned by Validate containing every route
+// which failed to register.
+type RegistrationErrors []*RouteError
+
+var _ error = RegistrationErrors(nil)
+
+// Error returns each route error on a separate line.
+func (re RegistrationErrors) Error() string {
+
+	buff := new(bytes.Buffer)
+
+	for i, e := range re {
+
+		if i > 0 {
+			buff.WriteByte('\n')
+		}
+
+		buff.WriteString(e.Error())
+	}
+
+	return buff.String()
+}
+
+// SetCollectRegistrationErrors tells lars whether to collect route registration errors,
+// i.e. conflicting routes, instead of panicking; routes which fail to register are
+// skipped and the errors returned by Validate. default false
+func (l *LARS) SetCollectRegistrationErrors(set bool) {
+	l.collectErrors = set
+}
+
+// Validate returns the RegistrationErrors of every route that failed to register,
+// when collecting registration errors, or nil if there were none.
+func (l *LARS) Validate() error {
+
+	if len(l.registrationErrors) == 0 {
+		return nil
+	}
+
+	errs := make(RegistrationErrors, len(l.registrationErrors))
+	copy(errs, l.registrationErrors)
+
+	return errs
+}
+
+// addRegistrationError records the route that failed to register with the recovered
+// panic and the existing route it conflicts with, if any.
+func (l *LARS) addRegistrationError(method string, path string, handlerName string, conflict *methodChain, r interface{}) {
+
+	e := &RouteError{
+		Method:      method,
+		Path:        path,
+		HandlerName: handlerName,
+		Message:     fmt.Sprint(r),
+	}
+
+	if conflict != nil {
+		e.ConflictPath = conflict.path
+		e.ConflictHandlerName = conflict.handlerName
+	}
+
+	l.registrationErrors = append(l.registrationErrors, e)
+}